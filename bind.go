@@ -0,0 +1,406 @@
+// MIT License
+
+// Copyright (c) 2020 Uday Hiwarale
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package clapper
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// type of `time.Duration` values
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// value types of the supported kinds of struct fields
+var bindingTypes = map[reflect.Kind]ValueType{
+	reflect.String:  TypeString,
+	reflect.Int:     TypeInt,
+	reflect.Int64:   TypeInt64,
+	reflect.Uint:    TypeUint,
+	reflect.Uint64:  TypeUint64,
+	reflect.Float64: TypeFloat64,
+	reflect.Bool:    TypeBool,
+}
+
+// a struct field bound to a flag or an argument
+type bindingField struct {
+	fieldName string
+	value     reflect.Value
+	name      string // name of the flag (like `no-clean`) or the argument
+	isFlag    bool
+	isSlice   bool
+	valueType ValueType
+	tag       reflect.StructTag
+}
+
+// ErrorInvalidBinding represents an error when a struct field can not be bound to a flag or an argument.
+// The `Name` field contains the name of the struct field (or the type of the bound value).
+type ErrorInvalidBinding struct {
+	Name   string
+	Reason string
+}
+
+func (e ErrorInvalidBinding) Error() string {
+	return fmt.Sprintf("invalid binding of %s: %s", e.Name, e.Reason)
+}
+
+/*---------------------*/
+
+// value type of a struct field (a slice of a supported type holds multiple values)
+func bindingType(fieldType reflect.Type) (valueType ValueType, isSlice bool, ok bool) {
+
+	if fieldType.Kind() == reflect.Slice {
+		isSlice, fieldType = true, fieldType.Elem()
+	}
+
+	if fieldType == durationType {
+		return TypeDuration, isSlice, true
+	}
+
+	valueType, ok = bindingTypes[fieldType.Kind()]
+	return
+}
+
+// struct fields with a `flag` or an `arg` tag
+func bindingFields(target interface{}) ([]bindingField, error) {
+
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return nil, ErrorInvalidBinding{fmt.Sprintf("%T", target), "expected a pointer to a struct"}
+	}
+
+	value = value.Elem()
+	fields := make([]bindingField, 0)
+
+	for index := 0; index < value.NumField(); index++ {
+		structField := value.Type().Field(index)
+
+		flagName, isFlag := structField.Tag.Lookup("flag")
+		argName, isArg := structField.Tag.Lookup("arg")
+
+		if !isFlag && !isArg {
+			continue
+		}
+
+		if isFlag && isArg {
+			return nil, ErrorInvalidBinding{structField.Name, "both flag and arg tags are set"}
+		}
+
+		if len(structField.PkgPath) != 0 {
+			return nil, ErrorInvalidBinding{structField.Name, "field is not exported"}
+		}
+
+		valueType, isSlice, ok := bindingType(structField.Type)
+		if !ok || (isSlice && valueType == TypeBool) {
+			return nil, ErrorInvalidBinding{structField.Name, fmt.Sprintf("unsupported type %s", structField.Type)}
+		}
+
+		name := flagName
+		if isArg {
+			name = strings.TrimSuffix(argName, "...")
+		}
+
+		if len(name) == 0 {
+			return nil, ErrorInvalidBinding{structField.Name, "empty name"}
+		}
+
+		fields = append(fields, bindingField{structField.Name, value.Field(index), name, isFlag, isSlice, valueType, structField.Tag})
+	}
+
+	return fields, nil
+}
+
+// convert a value to the type of a struct field (or its elements)
+func convertValue(value string, valueType ValueType, fieldType reflect.Type) (reflect.Value, bool) {
+
+	converted := reflect.New(fieldType).Elem()
+
+	switch valueType {
+	case TypeString:
+		converted.SetString(value)
+	case TypeInt, TypeInt64:
+		v, err := strconv.ParseInt(value, 0, fieldType.Bits())
+		if err != nil {
+			return converted, false
+		}
+
+		converted.SetInt(v)
+	case TypeUint, TypeUint64:
+		v, err := strconv.ParseUint(value, 0, fieldType.Bits())
+		if err != nil {
+			return converted, false
+		}
+
+		converted.SetUint(v)
+	case TypeFloat64:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return converted, false
+		}
+
+		converted.SetFloat(v)
+	case TypeBool:
+		v, ok := parseBoolLiteral(value)
+		if !ok {
+			return converted, false
+		}
+
+		converted.SetBool(v)
+	case TypeDuration:
+		v, err := time.ParseDuration(value)
+		if err != nil {
+			return converted, false
+		}
+
+		converted.SetInt(int64(v))
+	}
+
+	return converted, true
+}
+
+/*---------------------*/
+
+// register the flag of a field with the command
+func (commandConfig *CommandConfig) bindFlag(field bindingField, isRequired bool) *Flag {
+
+	flag, _ := commandConfig.AddTypedFlag(field.name, field.tag.Get("short"), field.valueType, field.tag.Get("default"))
+	flag.IsRequired = isRequired
+	flag.IsRepeatable = field.isSlice
+	flag.Description = field.tag.Get("help")
+
+	if env := field.tag.Get("env"); len(env) != 0 {
+		flag.EnvVars = strings.Split(removeWhitespaces(env), ",")
+	}
+
+	return flag
+}
+
+// register the argument of a field with the command
+func (commandConfig *CommandConfig) bindArg(field bindingField, isRequired bool) *Arg {
+
+	name := field.name
+	if field.isSlice {
+		name += "..."
+	}
+
+	arg, _ := commandConfig.AddTypedArg(name, field.valueType, field.tag.Get("default"))
+	arg.IsRequired = isRequired
+	arg.Description = field.tag.Get("help")
+
+	return arg
+}
+
+// check if a field can be bound to a flag or an argument of the command, the reason is returned if it can not
+// a flag or an argument registered before must have the same settings as the field
+func (commandConfig *CommandConfig) checkBinding(field bindingField, isRequired bool) string {
+
+	// the default value of a boolean flag is fixed
+	if defaultValue := field.tag.Get("default"); field.isFlag && field.valueType == TypeBool && len(defaultValue) != 0 {
+		isInverted := strings.HasPrefix(field.name, "no-")
+
+		if value, ok := parseBoolLiteral(defaultValue); !ok || value != isInverted {
+			return fmt.Sprintf("default value of a boolean flag must be %t", isInverted)
+		}
+	}
+
+	// settings of the field applied to a new command
+	expected := newCommandConfig("", nil)
+
+	if field.isFlag {
+		expectedFlag := expected.bindFlag(field, isRequired)
+
+		flag, ok := commandConfig.Flags[expectedFlag.Name]
+		if ok && (flag.ShortName != expectedFlag.ShortName || flag.IsBoolean != expectedFlag.IsBoolean || flag.IsInverted != expectedFlag.IsInverted ||
+			flag.DefaultValue != expectedFlag.DefaultValue || flag.Type != expectedFlag.Type || flag.IsRequired != expectedFlag.IsRequired ||
+			flag.IsRepeatable != expectedFlag.IsRepeatable || flag.Description != expectedFlag.Description || !reflect.DeepEqual(flag.EnvVars, expectedFlag.EnvVars)) {
+			return fmt.Sprintf("flag --%s is already registered with different settings", flag.Name)
+		}
+	} else {
+		expectedArg := expected.bindArg(field, isRequired)
+
+		arg, ok := commandConfig.Args[expectedArg.Name]
+		if ok && (arg.IsVariadic != expectedArg.IsVariadic || arg.DefaultValue != expectedArg.DefaultValue || arg.Type != expectedArg.Type ||
+			arg.IsRequired != expectedArg.IsRequired || arg.Description != expectedArg.Description) {
+			return fmt.Sprintf("argument %s is already registered with different settings", arg.Name)
+		}
+	}
+
+	return ""
+}
+
+// Bind method registers flags and arguments of the command using the tagged fields of a struct.
+// The `target` argument must be a pointer to a struct. The supported tags of a field are:
+//
+//	flag:"name"     long name of the flag (a boolean flag with `no-` prefix is an inverted flag)
+//	arg:"name"      name of the argument (registered in the order of the fields)
+//	short:"n"       short name of the flag
+//	default:"value" default value of the flag or the argument
+//	env:"A,B"       names of the environment variables bound to the flag
+//	required:"true" the flag or the argument must be provided
+//	help:"text"     description of the flag or the argument
+//
+// The supported field types are `string`, `int`, `int64`, `uint`, `uint64`, `float64`, `bool`, `time.Duration`
+// and slices of these types (except `bool`). A slice field is bound to a repeatable flag or a variadic argument.
+// The default value of a boolean flag is "false" (and "true" for an inverted flag), another `default` tag is rejected.
+// A flag or an argument already registered with the command must have the same settings as the field.
+// If a field can not be bound, it returns `ErrorInvalidBinding` error and nothing is registered.
+func (commandConfig *CommandConfig) Bind(target interface{}) error {
+
+	fields, err := bindingFields(target)
+	if err != nil {
+		return err
+	}
+
+	// check all fields before registering the flags and the arguments
+	required := make([]bool, len(fields))
+	boundFields := make(map[string]string)
+
+	for index, field := range fields {
+		key := "argument " + field.name
+		if field.isFlag {
+			key = "flag --" + strings.TrimPrefix(field.name, "no-")
+		}
+
+		if fieldName, ok := boundFields[key]; ok {
+			return ErrorInvalidBinding{field.fieldName, fmt.Sprintf("%s is already bound to field %s", key, fieldName)}
+		}
+
+		boundFields[key] = field.fieldName
+
+		if tag, ok := field.tag.Lookup("required"); ok {
+			if required[index], err = strconv.ParseBool(tag); err != nil {
+				return ErrorInvalidBinding{field.fieldName, fmt.Sprintf("invalid required tag %s", strconv.Quote(tag))}
+			}
+		}
+
+		if reason := commandConfig.checkBinding(field, required[index]); len(reason) != 0 {
+			return ErrorInvalidBinding{field.fieldName, reason}
+		}
+	}
+
+	for index, field := range fields {
+		if field.isFlag {
+			commandConfig.bindFlag(field, required[index])
+		} else {
+			commandConfig.bindArg(field, required[index])
+		}
+	}
+
+	return nil
+}
+
+// decode values of the flags and the arguments into the tagged fields of a struct
+func decode(flags map[string]*Flag, args map[string]*Arg, target interface{}) error {
+
+	fields, err := bindingFields(target)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+
+		// effective values of the flag or the argument
+		var name string
+		var values []string
+		var source ValueSource
+
+		if field.isFlag {
+			flagName := field.name
+			if strings.HasPrefix(flagName, "no-") && field.valueType == TypeBool {
+				flagName = strings.TrimPrefix(flagName, "no-")
+			}
+
+			flag, ok := flags[flagName]
+			if !ok {
+				return ErrorInvalidBinding{field.fieldName, "flag is not registered"}
+			}
+
+			name, source = "--"+flag.Name, flag.Source
+
+			if !field.isSlice {
+				values = []string{flag.Effective()}
+			} else if flag.IsRepeatable && len(flag.Values) > 0 {
+				values = flag.Values
+			} else {
+				values = flag.StringSlice()
+			}
+		} else {
+			arg, ok := args[field.name]
+			if !ok {
+				return ErrorInvalidBinding{field.fieldName, "argument is not registered"}
+			}
+
+			name, source = arg.Name, arg.Source
+
+			if field.isSlice {
+				values = arg.StringSlice()
+			} else {
+				values = []string{arg.Effective()}
+			}
+		}
+
+		// a field without a value keeps its zero value
+		fieldType := field.value.Type()
+		if field.isSlice {
+			fieldType = fieldType.Elem()
+		} else if len(values[0]) == 0 {
+			field.value.Set(reflect.Zero(fieldType))
+			continue
+		}
+
+		converted := make([]reflect.Value, 0, len(values))
+		for _, value := range values {
+			v, ok := convertValue(value, field.valueType, fieldType)
+			if !ok {
+				return ErrorInvalidValue{name, value, field.valueType, source}
+			}
+
+			converted = append(converted, v)
+		}
+
+		if field.isSlice {
+			slice := reflect.MakeSlice(field.value.Type(), 0, len(converted))
+			field.value.Set(reflect.Append(slice, converted...))
+		} else {
+			field.value.Set(converted[0])
+		}
+	}
+
+	return nil
+}
+
+// Decode method sets the tagged fields of a struct (see `CommandConfig.Bind` method) using the effective values
+// of the flags and the arguments of the result, converted to the types of the fields.
+// If a value can not be converted, it returns `ErrorInvalidValue` error.
+func (result *ParseResult) Decode(target interface{}) error {
+	return decode(result.Flags, result.Args, target)
+}
+
+// Decode method sets the tagged fields of a struct (see `Bind` method) using the effective values
+// of the flags and the arguments of the command returned by the `Registry.Parse` method.
+// If a value can not be converted, it returns `ErrorInvalidValue` error.
+func (commandConfig *CommandConfig) Decode(target interface{}) error {
+	return decode(commandConfig.Flags, commandConfig.Args, target)
+}
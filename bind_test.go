@@ -0,0 +1,202 @@
+package clapper
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// options of the `deploy` command used to test struct binding
+type deployOptions struct {
+	Environment string        `arg:"environment" required:"true" help:"target environment"`
+	Services    []string      `arg:"services"`
+	Replicas    int           `flag:"replicas" short:"r" default:"2" help:"number of replicas"`
+	Timeout     time.Duration `flag:"timeout" default:"30s"`
+	Ratio       float64       `flag:"ratio" env:"RATIO, CANARY_RATIO"`
+	Force       bool          `flag:"force" short:"f"`
+	Cache       bool          `flag:"no-cache"`
+	Labels      []string      `flag:"label" short:"l"`
+	Ports       []uint        `flag:"port" default:"80,443"`
+	Token       string        `flag:"token" required:"true"`
+	Ignored     string
+}
+
+// test binding parse results to structs
+func TestBind(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+	deployCommand, _ := registry.Register("deploy")
+
+	if err := deployCommand.Bind(&deployOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// registered flags and arguments
+	if flag := deployCommand.Flags["replicas"]; flag.ShortName != "r" || flag.Type != TypeInt || flag.DefaultValue != "2" || flag.Description != "number of replicas" {
+		t.Errorf("unexpected flag: %#v", flag)
+	}
+
+	if flag := deployCommand.Flags["ratio"]; !reflect.DeepEqual(flag.EnvVars, []string{"RATIO", "CANARY_RATIO"}) {
+		t.Errorf("unexpected environment variables: %#v", flag.EnvVars)
+	}
+
+	if flag := deployCommand.Flags["cache"]; !flag.IsBoolean || !flag.IsInverted {
+		t.Errorf("expected inverted flag, got %#v", flag)
+	}
+
+	if flag := deployCommand.Flags["label"]; !flag.IsRepeatable || !deployCommand.Flags["token"].IsRequired {
+		t.Errorf("unexpected flag: %#v", flag)
+	}
+
+	if !reflect.DeepEqual(deployCommand.ArgNames, []string{"environment", "services"}) || !deployCommand.Args["services"].IsVariadic || !deployCommand.Args["environment"].IsRequired {
+		t.Errorf("unexpected arguments: %#v", deployCommand.ArgNames)
+	}
+
+	// decode values into a struct
+	result, err := registry.ParseArgs([]string{"deploy", "production", "api", "web", "-r", "3", "--ratio=0.5", "-f", "--no-cache", "-l", "a", "-l", "b", "--token", "secret"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	options := deployOptions{Ignored: "kept"}
+	if err := result.Decode(&options); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := deployOptions{
+		Environment: "production",
+		Services:    []string{"api", "web"},
+		Replicas:    3,
+		Timeout:     30 * time.Second,
+		Ratio:       0.5,
+		Force:       true,
+		Cache:       false,
+		Labels:      []string{"a", "b"},
+		Ports:       []uint{80, 443},
+		Token:       "secret",
+		Ignored:     "kept",
+	}
+
+	if !reflect.DeepEqual(options, expected) {
+		t.Errorf("expected %#v, got %#v", expected, options)
+	}
+
+	// a slice field is filled from the comma-separated values of an environment variable
+	deployCommand.Flags["port"].EnvVars = []string{"CLAPPER_TEST_PORTS"}
+	os.Setenv("CLAPPER_TEST_PORTS", "8080,8443")
+	defer os.Unsetenv("CLAPPER_TEST_PORTS")
+
+	if result, err = registry.ParseArgs([]string{"deploy", "production", "--token", "secret"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := result.Decode(&options); err != nil || !reflect.DeepEqual(options.Ports, []uint{8080, 8443}) {
+		t.Errorf("expected ports [8080 8443], got %#v (%v)", options.Ports, err)
+	}
+
+	// values are checked against the types of the fields
+	if _, err := registry.ParseArgs([]string{"deploy", "production", "--token", "secret", "--port", "http"}); err != (ErrorInvalidValue{"--port", "http", TypeUint, SourceArgv}) {
+		t.Errorf("expected invalid value error, got %#v", err)
+	}
+}
+
+// test invalid binding targets and values
+func TestInvalidBind(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+	rootCommand, _ := registry.Register("")
+
+	errs := []struct {
+		target   interface{}
+		expected error
+	}{
+		{deployOptions{}, ErrorInvalidBinding{"clapper.deployOptions", "expected a pointer to a struct"}},
+		{&struct {
+			Values map[string]string `flag:"values"`
+		}{}, ErrorInvalidBinding{"Values", "unsupported type map[string]string"}},
+		{&struct {
+			Flags []bool `flag:"flags"`
+		}{}, ErrorInvalidBinding{"Flags", "unsupported type []bool"}},
+		{&struct {
+			Name string `flag:"name" arg:"name"`
+		}{}, ErrorInvalidBinding{"Name", "both flag and arg tags are set"}},
+		{&struct {
+			Name string `flag:"name" required:"maybe"`
+		}{}, ErrorInvalidBinding{"Name", `invalid required tag "maybe"`}},
+		{&struct {
+			Debug bool `flag:"debug" default:"true"`
+		}{}, ErrorInvalidBinding{"Debug", "default value of a boolean flag must be false"}},
+		{&struct {
+			Clean bool `flag:"no-clean" default:"no"`
+		}{}, ErrorInvalidBinding{"Clean", "default value of a boolean flag must be true"}},
+		{&struct {
+			Name  string `flag:"name"`
+			Alias string `flag:"name"`
+		}{}, ErrorInvalidBinding{"Alias", "flag --name is already bound to field Name"}},
+	}
+
+	for _, e := range errs {
+		if err := rootCommand.Bind(e.target); err != e.expected {
+			t.Errorf("expected %#v, got %#v", e.expected, err)
+		}
+	}
+
+	// flags and arguments registered before with different settings
+	rootCommand.AddFlag("output", "o", false, "./")
+	rootCommand.AddArg("target", "")
+
+	conflicts := []struct {
+		target   interface{}
+		expected error
+	}{
+		{&struct {
+			Output int `flag:"output" short:"o" default:"./"`
+		}{}, ErrorInvalidBinding{"Output", "flag --output is already registered with different settings"}},
+		{&struct {
+			Output string `flag:"output" short:"o" default:"./" required:"true"`
+		}{}, ErrorInvalidBinding{"Output", "flag --output is already registered with different settings"}},
+		{&struct {
+			Force   bool     `flag:"force"`
+			Targets []string `arg:"target"`
+		}{}, ErrorInvalidBinding{"Targets", "argument target is already registered with different settings"}},
+	}
+
+	for _, e := range conflicts {
+		if err := rootCommand.Bind(e.target); err != e.expected {
+			t.Errorf("expected %#v, got %#v", e.expected, err)
+		}
+	}
+
+	// nothing is registered by a failed binding
+	if _, ok := rootCommand.Flags["force"]; ok || rootCommand.Flags["output"].IsRequired {
+		t.Errorf("unexpected flags: %#v", rootCommand.Flags)
+	}
+
+	// a flag registered before with the same settings
+	if err := rootCommand.Bind(&struct {
+		Output string `flag:"output" short:"o" default:"./"`
+	}{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// invalid default value of a field
+	options := struct {
+		Port int `flag:"port" default:"http"`
+	}{}
+
+	if err := rootCommand.Bind(&options); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := registry.ParseArgs([]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := result.Decode(&options); err != (ErrorInvalidValue{"--port", "http", TypeInt, SourceDefault}) {
+		t.Errorf("expected invalid value error, got %#v", err)
+	}
+}
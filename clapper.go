@@ -27,6 +27,10 @@ package clapper
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -34,27 +38,20 @@ import (
         PRIVATE FUNCTIONS AND VARIABLES
 ***********************************************/
 
-// format command-line argument values
-func formatCommandValues(values []string) (formatted []string) {
+// split a flag and the value attached with the first `=` (like `--dir=value` or `--define=key=value`)
+// (a cluster of combined short flags handles `=` itself, like `-vo=file`)
+func splitAttachedValue(value string) (flag string, attachedValue string, hasAttachedValue bool) {
 
-	formatted = make([]string, 0)
-
-	// split a value by `=`
-	for _, value := range values {
-		if isFlag(value) {
-			parts := strings.Split(value, "=")
-
-			for _, part := range parts {
-				if strings.Trim(part, " ") != "" {
-					formatted = append(formatted, part)
-				}
-			}
-		} else {
-			formatted = append(formatted, value)
-		}
+	if index := strings.Index(value, "="); index >= 0 && isFlag(value) && !isShortFlagCluster(value[:index]) {
+		return value[:index], value[index+1:], true
 	}
 
-	return
+	return value, "", false
+}
+
+// check if value is the `--` terminator which marks the end of flags
+func isEndOfFlags(value string) bool {
+	return value == "--"
 }
 
 // check if value is a flag
@@ -62,11 +59,68 @@ func isFlag(value string) bool {
 	return len(value) >= 2 && strings.HasPrefix(value, "-")
 }
 
+// check if value is a negative number (like `-1`, `-1.5` or `-0x10`), which is taken as a value rather than a flag
+func isNegativeNumber(value string) bool {
+
+	if !isFlag(value) || !strings.ContainsAny(value[1:2], "0123456789.") {
+		return false
+	}
+
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return true
+	}
+
+	_, err := strconv.ParseInt(value, 0, 64)
+	return err == nil
+}
+
 // check if value is a short flag
 func isShortFlag(value string) bool {
 	return isFlag(value) && len(value) == 2 && !strings.HasPrefix(value, "--")
 }
 
+// check if value is a cluster of combined short flags (like `-xvf`)
+func isShortFlagCluster(value string) bool {
+	return isFlag(value) && len(value) > 2 && !strings.HasPrefix(value, "--")
+}
+
+// split a cluster of combined short flags into individual short flags
+// the first flag of the cluster which takes a value consumes the rest of the cluster (`-ofile` or `-o=file`) as its value
+func splitShortFlagCluster(value string, commandConfig *CommandConfig) (flags []string, attachedValue string, hasAttachedValue bool, err error) {
+
+	names := strings.Split(strings.TrimPrefix(value, "-"), "")
+	flags = make([]string, 0, len(names))
+
+	for index, name := range names {
+
+		// check if short flag is registered
+		flagName, ok := commandConfig.flagsShort[name]
+		if !ok {
+
+			// built-in help flag
+			if isHelpFlag("-"+name, commandConfig) {
+				flags = append(flags, "-"+name)
+				continue
+			}
+
+			return nil, "", false, commandConfig.unknownFlag("-" + name)
+		}
+
+		flags = append(flags, "-"+name)
+
+		// rest of the cluster is the value of a flag which takes a value
+		if !commandConfig.Flags[flagName].IsBoolean {
+			if rest := strings.Join(names[index+1:], ""); len(rest) > 0 {
+				return flags, strings.TrimPrefix(rest, "="), true, nil
+			}
+
+			break
+		}
+	}
+
+	return flags, "", false, nil
+}
+
 // check if value starts with `--no-` prefix
 func isInvertedFlag(value string) (bool, string) {
 	if isFlag(value) && strings.HasPrefix(value, "--no-") {
@@ -87,8 +141,9 @@ func isUnsupportedFlag(value string) bool {
 			return !strings.HasPrefix(value, "-") || strings.HasPrefix(value, "--")
 		}
 
-		// if long flag, it should start with `--` and not with `---`
-		return !strings.HasPrefix(value, "--") || strings.HasPrefix(value, "---")
+		// if long flag, it should not start with `---`
+		// (a value with a single `-` prefix is a cluster of combined short flags)
+		return !strings.HasPrefix(value, "-") || strings.HasPrefix(value, "---")
 	}
 
 	return false
@@ -104,10 +159,10 @@ func isVariadicArgument(value string) (bool, string) {
 }
 
 // check if values corresponds to the root command
-func isRootCommand(values []string, registry Registry) bool {
+func isRootCommand(values []string, registry *Registry) bool {
 
 	// FALSE: if the root command is not registered
-	if _, ok := registry[""]; !ok {
+	if _, ok := registry.Commands[""]; !ok {
 		return false
 	}
 
@@ -117,11 +172,11 @@ func isRootCommand(values []string, registry Registry) bool {
 	}
 
 	// get root `CommandConfig` value from the registry
-	rootCommandConfig := registry[""]
+	rootCommandConfig := registry.Commands[""]
 
-	// TRUE: if the first value is not a registered command
+	// TRUE: if the first value is not a registered command (an empty value is not a command name)
 	// and some arguments are registered for the root command
-	if _, ok := registry[values[0]]; len(rootCommandConfig.Args) > 0 && !ok {
+	if len(rootCommandConfig.Args) > 0 && !registry.isCommandName(registry.Commands, values[0]) {
 		return true
 	}
 
@@ -160,21 +215,34 @@ func removeWhitespaces(value string) string {
 /***********************************************/
 
 // ErrorUnknownCommand represents an error when command-line arguments contain an unregistered command.
+// The `Suggestions` field contains the registered command names similar to the unknown command name.
 type ErrorUnknownCommand struct {
-	Name string
+	Name        string
+	Suggestions []string
 }
 
 func (e ErrorUnknownCommand) Error() string {
-	return fmt.Sprintf("unknown command %s found in the arguments", e.Name)
+	return fmt.Sprintf("unknown command %s found in the arguments%s", e.Name, didYouMean(e.Suggestions))
 }
 
 // ErrorUnknownFlag represents an error when command-line arguments contain an unregistered flag.
+// The `Suggestions` field contains the registered flags similar to the unknown flag.
 type ErrorUnknownFlag struct {
-	Name string
+	Name        string
+	Suggestions []string
 }
 
 func (e ErrorUnknownFlag) Error() string {
-	return fmt.Sprintf("unknown flag %s found in the arguments", e.Name)
+	return fmt.Sprintf("unknown flag %s found in the arguments%s", e.Name, didYouMean(e.Suggestions))
+}
+
+// suggestions appended to an error message
+func didYouMean(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(" (did you mean %s?)", strings.Join(suggestions, ", "))
 }
 
 // ErrorUnsupportedFlag represents an error when command-line arguments contain an unsupported flag.
@@ -186,45 +254,189 @@ func (e ErrorUnsupportedFlag) Error() string {
 	return fmt.Sprintf("unsupported flag %s found in the arguments", e.Name)
 }
 
+// ErrorMissingFlagValue represents an error when a flag which takes a value is the last command-line argument
+// or it is followed by another flag (see the `IsValueOptional` field of the `Flag`).
+type ErrorMissingFlagValue struct {
+	Name string
+}
+
+func (e ErrorMissingFlagValue) Error() string {
+	return fmt.Sprintf("no value of flag %s found in the arguments", e.Name)
+}
+
+// ErrorMissingRequired represents an error when command-line arguments do not contain required arguments or flags.
+// The `Names` field contains the names of the missing arguments and flags (with `--` prefix).
+type ErrorMissingRequired struct {
+	Names []string
+}
+
+func (e ErrorMissingRequired) Error() string {
+	return fmt.Sprintf("required %s not found in the arguments", strings.Join(e.Names, ", "))
+}
+
+// ErrorArgumentCount represents an error when the number of values of a variadic argument is out of its limits.
+// A zero `Max` value means that the number of values is not limited.
+type ErrorArgumentCount struct {
+	Name  string
+	Count int
+	Min   int
+	Max   int
+}
+
+func (e ErrorArgumentCount) Error() string {
+	if e.Max > 0 {
+		return fmt.Sprintf("argument %s accepts %d to %d values but %d found in the arguments", e.Name, e.Min, e.Max, e.Count)
+	}
+
+	return fmt.Sprintf("argument %s accepts at least %d values but %d found in the arguments", e.Name, e.Min, e.Count)
+}
+
+// ErrorTooManyArguments represents an error when command-line arguments contain more values than the arguments of the command can hold
+// (see the `StrictArgs` field of the `Registry`). The `Values` field contains the surplus values.
+type ErrorTooManyArguments struct {
+	Values []string
+}
+
+func (e ErrorTooManyArguments) Error() string {
+	return fmt.Sprintf("too many arguments, unexpected values %s found in the arguments", strings.Join(e.Values, ", "))
+}
+
 /*---------------------*/
 
-// Registry holds the configuration of the registered commands.
-type Registry map[string]*CommandConfig
+// Registry holds the configuration of the registered commands and the settings of the parser.
+// The registered commands are held in the `Commands` field (in v1 of the module, the `Registry` was a map of the commands).
+// A `*Registry` object must be created using the `NewRegistry` function.
+type Registry struct {
+
+	// registered commands ("" for the root command)
+	Commands map[string]*CommandConfig
+
+	// prefix of the names of the environment variables bound to the flags (like "APP_")
+	EnvPrefix string
+
+	// values of the flags loaded from config files keyed by the command path and the flag name
+	config map[string]map[string][]string
+
+	// functions invoked before and after the handler of every command
+	BeforeHooks []HandlerFunc
+	AfterHooks  []AfterHookFunc
+
+	// functions wrapping the run step of every command
+	Middlewares []MiddlewareFunc
+
+	// accept unique prefixes of the command names and the long flag names (like `--verb` for `--verbose`)
+	AllowPrefixes bool
+
+	// reject values which are not held by any argument of the command (instead of discarding them)
+	StrictArgs bool
+
+	// writer of the usage text and the completion candidates printed by the `Execute` method (`os.Stdout` if nil)
+	Output io.Writer
+}
 
 // Register method registers a command.
 // The "name" argument should be a simple string.
 // If "name" is an empty string, it is considered as a root command.
-// If a command is already registered, the registered `*CommandConfig` object is returned.
+// The optional "aliases" are alternative names of the command (like "rm" for "remove").
+// If a command is already registered, the registered `*CommandConfig` object is returned (with the new aliases added).
 // If the command is already registered, second return value will be `true`.
-func (registry Registry) Register(name string) (*CommandConfig, bool) {
+// It panics if an alias (or the name) is already used by another command registered at the same level.
+func (registry *Registry) Register(name string, aliases ...string) (*CommandConfig, bool) {
 
 	// remove all whitespaces
 	commandName := removeWhitespaces(name)
 
 	// check if command is already registered, if found, return existing entry
-	if _commandConfig, ok := registry[commandName]; ok {
+	if _commandConfig, ok := registry.Commands[commandName]; ok {
+		_commandConfig.addAliases(registry.Commands, aliases)
 		return _commandConfig, true
 	}
 
 	// construct new `CommandConfig` object
-	commandConfig := &CommandConfig{
-		Name:       commandName,
-		Flags:      make(map[string]*Flag),
-		flagsShort: make(map[string]string),
-		Args:       make(map[string]*Arg),
-		ArgNames:   make([]string, 0),
-	}
+	commandConfig := newCommandConfig(commandName, nil)
+	commandConfig.checkName(registry.Commands)
+	commandConfig.addAliases(registry.Commands, aliases)
 
 	// add entry to the registry
-	registry[commandName] = commandConfig
+	registry.Commands[commandName] = commandConfig
 
 	return commandConfig, false
 }
 
 // Parse method parses command-line arguments and returns an appropriate "*CommandConfig" object registered in the registry.
+// The values of the flags and the arguments are written into the registered `*Flag` and `*Arg` objects,
+// hence it must not be called concurrently. See the `ParseArgs` method for details and errors.
+func (registry *Registry) Parse(values []string) (*CommandConfig, error) {
+
+	result, err := registry.ParseArgs(values)
+	if err != nil {
+		return nil, err
+	}
+
+	// write values into the registered flags (including the inherited persistent flags) and arguments
+	for flagName, flag := range result.Flags {
+		if registered, ok := result.Command.Flags[flagName]; ok {
+			*registered = *flag
+			continue
+		}
+
+		for _, registered := range registry.inheritedFlags(result.Command) {
+			if registered.Name == flagName {
+				*registered = *flag
+				break
+			}
+		}
+	}
+
+	for argName, arg := range result.Args {
+		*result.Command.Args[argName] = *arg
+	}
+
+	// write values of the flags of the root command provided before the command name
+	if rootCommandConfig, ok := registry.Commands[""]; ok && result.Command != rootCommandConfig {
+		for flagName, flag := range result.RootFlags {
+			if registered, ok := rootCommandConfig.Flags[flagName]; ok {
+				*registered = *flag
+			}
+		}
+	}
+
+	return result.Command, nil
+}
+
+// ParseArgs method parses command-line arguments and returns a `*ParseResult` object holding the values of the flags and the arguments
+// of an appropriate command registered in the registry. The registered flags and arguments are not modified,
+// hence a registry can be used to parse command-line arguments concurrently.
+// Sub-command names are matched level by level and the deepest matching command is returned.
+// Persistent flags of the root command and the parent commands are inherited by the matched command
+// and they can precede the sub-command names, like `remote --verbose add`.
+// Flags of the root command can precede the command name, like `--verbose info`, and their values are held
+// in the `RootFlags` field of the result.
+// The `Path` field of the matched command holds the full path of the command, such as "remote add".
+// A command is matched by its name or an alias, or by a unique prefix of them if the `AllowPrefixes` field is set.
 // If command is not registered, it return `ErrorUnknownCommand` error.
+// If a prefix of a command name or a long flag name matches more than one command or flag, it returns `ErrorAmbiguous` error.
+// Combined short flags such as `-xvf` are processed as `-x -v -f`.
+// A short flag which takes a value accepts an attached value such as `-ofile` or `-o=file`,
+// otherwise the value is taken from the next command-line argument.
+// All values after the `--` terminator are processed as arguments, even if they start with `-` prefix.
 // If there is an error parsing a flag, it can return an `ErrorUnknownFlag` or `ErrorUnsupportedFlag` error.
-func (registry Registry) Parse(values []string) (*CommandConfig, error) {
+// If a flag which takes a value is the last value or it is followed by another flag, it returns `ErrorMissingFlagValue` error.
+// A value attached to a flag with `=` (like `--define=key=value`) is split from the flag by the first `=`.
+// A boolean flag accepts an attached boolean literal (`true`, `false`, `1`, `0`, `yes` or `no`) such as `--force=false`,
+// otherwise it returns `ErrorInvalidValue` error.
+// If the first value is the hidden `__complete` command, it returns `ErrorCompletionRequested` error
+// containing the completion candidates of the last value (nothing is printed).
+// If the built-in `--help` or `-h` flag is provided and not registered by the command,
+// it returns `ErrorHelpRequested` error containing the usage text of the command.
+// If a flag or an argument value can not be converted to its registered type, it returns `ErrorInvalidValue` error.
+// The value of a flag absent from the command-line arguments is taken from the environment variables bound to the flag
+// or the loaded config files. The `IsSet` and `Source` fields of the flags and the arguments tell where their values came from.
+// If required flags or arguments are not provided, it returns `ErrorMissingRequired` error.
+// If the number of values of a variadic argument is out of its limits, it returns `ErrorArgumentCount` error.
+// Values which are not held by any argument of the command are discarded, unless the `StrictArgs` field is set,
+// in which case it returns `ErrorTooManyArguments` error.
+func (registry *Registry) ParseArgs(values []string) (*ParseResult, error) {
 
 	// command name
 	var commandName string
@@ -232,6 +444,32 @@ func (registry Registry) Parse(values []string) (*CommandConfig, error) {
 	// command-line argument values to process
 	valuesToProcess := values
 
+	// completion candidates for the hidden `__complete` command (printed by the caller)
+	if len(values) > 0 && values[0] == "__complete" {
+		return nil, ErrorCompletionRequested{registry.complete(values[1:])}
+	}
+
+	// list registered commands if the root command is not registered
+	if _, ok := registry.Commands[""]; !ok && len(values) > 0 && (values[0] == "--help" || values[0] == "-h") {
+		return nil, ErrorHelpRequested{"", registry.Help(programName(), nil)}
+	}
+
+	// flags of the root command preceding the command name (like `--verbose` in `--verbose info`)
+	rootFlagValues := make([]string, 0)
+
+	if rootCommandConfig, ok := registry.Commands[""]; ok && len(values) > 0 && isFlag(values[0]) {
+		flagValues, rest := registry.splitLeadingFlags(values, rootCommandConfig, false)
+
+		if len(flagValues) > 0 && len(rest) > 0 && len(rest[0]) != 0 {
+			if registry.isCommandName(registry.Commands, rest[0]) {
+				rootFlagValues, values = flagValues, rest
+			}
+		}
+	}
+
+	// persistent flags preceding the sub-command names are processed as flags of the matched command
+	hoisted := make([]string, 0)
+
 	// check if command is a root command
 	if isRootCommand(values, registry) {
 		commandName = "" // root command name
@@ -239,126 +477,508 @@ func (registry Registry) Parse(values []string) (*CommandConfig, error) {
 		commandName, valuesToProcess = nextValue(values)
 	}
 
-	// format command-line argument values
-	valuesToProcess = formatCommandValues(valuesToProcess)
-
 	// check for invalid flag structure
 	for _, val := range valuesToProcess {
-		if isFlag(val) && isUnsupportedFlag(val) {
-			return nil, ErrorUnsupportedFlag{val}
+		if isEndOfFlags(val) {
+			break
+		}
+
+		if flag, _, _ := splitAttachedValue(val); isFlag(flag) && isUnsupportedFlag(flag) {
+			return nil, ErrorUnsupportedFlag{flag}
 		}
 	}
 
+	// get `CommandConfig` object from the registry (by the name, an alias or a prefix),
 	// if command is not registered, return `ErrorUnknownCommand` error
-	if _, ok := registry[commandName]; !ok {
-		return nil, ErrorUnknownCommand{commandName}
+	commandConfig, err := registry.lookupCommand(registry.Commands, commandName)
+	if err != nil {
+		return nil, err
 	}
 
-	// get `CommandConfig` object from the registry
-	commandConfig := registry[commandName]
+	// walk the sub-command tree to find the deepest matching command
+	for len(valuesToProcess) > 0 && len(commandConfig.SubCommands) > 0 {
+
+		// persistent flags may precede the sub-command name
+		flagValues, rest := registry.splitLeadingFlags(valuesToProcess, commandConfig, true)
+		if len(rest) == 0 || isFlag(rest[0]) {
+			break
+		}
+
+		// descend if value is a registered sub-command
+		subCommandConfig, err := registry.lookupCommand(commandConfig.SubCommands, rest[0])
+		if err == nil {
+			commandConfig = subCommandConfig
+			hoisted = append(hoisted, flagValues...)
+			_, valuesToProcess = nextValue(rest)
+			continue
+		}
+
+		// if the command does not accept arguments, value must be an unknown sub-command
+		if _, isAmbiguous := err.(ErrorAmbiguous); isAmbiguous || len(commandConfig.Args) == 0 {
+			return nil, err
+		}
+
+		break
+	}
+
+	valuesToProcess = append(hoisted, valuesToProcess...)
+
+	// values are set on copies of the registered flags (including the inherited persistent flags) and arguments
+	definition := commandConfig
+	commandConfig = registry.resolve(commandConfig)
+
+	// flags of the root command are processed by a copy of the root command
+	rootCommandConfig := commandConfig
+	if registeredRoot, ok := registry.Commands[""]; ok && definition != registeredRoot {
+		rootCommandConfig = registry.resolve(registeredRoot)
+
+		for len(rootFlagValues) > 0 {
+			var value string
+			var err error
+
+			value, rootFlagValues = nextValue(rootFlagValues)
+			if rootFlagValues, err = registry.parseFlag(rootCommandConfig, value, rootFlagValues); err != nil {
+				return nil, err
+			}
+		}
+
+		// persistent flags of the root command are inherited by the matched command (unless overridden)
+		inheritedFlags := registry.inheritedFlags(definition)
+		for _, flagName := range rootCommandConfig.flagNames() {
+			if _, ok := definition.Flags[flagName]; ok {
+				continue
+			}
+
+			for _, flag := range inheritedFlags {
+				if flag.Name != flagName {
+					continue
+				}
+
+				if flag == registeredRoot.Flags[flagName] {
+					commandConfig.Flags[flagName] = rootCommandConfig.Flags[flagName]
+				}
+
+				break
+			}
+		}
+	}
+
+	// if the `--` terminator is found, remaining values are processed as arguments
+	isTerminated := false
+
+	// values not held by any argument
+	surplus := make([]string, 0)
 
 	// process all command-line arguments (except command name)
-	for {
+	for len(valuesToProcess) > 0 {
 
 		// get current command-line argument value
 		var value string
 		value, valuesToProcess = nextValue(valuesToProcess)
 
-		// if `value` is empty, break the loop
-		if len(value) == 0 {
-			break
+		// stop processing flags after the `--` terminator
+		if !isTerminated && isEndOfFlags(value) {
+			isTerminated = true
+			continue
+		}
+
+		// a negative number is an argument value if the next argument is numeric (like `-5` in `move -5`)
+		isNumericArg := false
+		if arg := commandConfig.nextArg(); arg != nil && isNumericType(arg.Type) {
+			isNumericArg = isNegativeNumber(value)
 		}
 
 		// check if `value` is a `flag` or an `argument`
-		if isFlag(value) {
+		if !isTerminated && isFlag(value) && !isNumericArg {
 
-			// trim `-` characters from the `value`
-			name := strings.TrimLeft(value, "-")
+			var err error
+			if valuesToProcess, err = registry.parseFlag(commandConfig, value, valuesToProcess); err != nil {
+				return nil, err
+			}
+		} else {
+
+			// process as argument
+			isHeld := false
+			for index, argName := range commandConfig.ArgNames {
 
-			// get flag object stored in the `commandConfig`
-			var flag *Flag
+				// get argument object stored in the `commandConfig`
+				arg := commandConfig.Args[argName]
 
-			// check if flag is short or long
-			if isShortFlag(value) {
-				if _, ok := commandConfig.flagsShort[name]; !ok {
-					return nil, ErrorUnknownFlag{value}
+				// check if argument value can be converted to the registered type
+				isLastArg := index == len(commandConfig.ArgNames)-1
+				if (!arg.IsSet || (isLastArg && arg.IsVariadic)) && len(value) != 0 && !isValidValue(value, arg.Type) {
+					return nil, ErrorInvalidValue{argName, value, arg.Type, SourceArgv}
 				}
 
-				// get long flag name
-				flagName := commandConfig.flagsShort[name]
+				// assign value if the argument is not set yet
+				if !arg.IsSet {
+					arg.Value = value
+					arg.Values = append(arg.Values, value)
+					arg.IsSet, arg.Source = true, SourceArgv
+					isHeld = true
+					break
+				}
 
-				flag = commandConfig.Flags[flagName]
-			} else {
+				// if last argument is a variadic argument, append values
+				if isLastArg && arg.IsVariadic {
+					arg.Value += fmt.Sprintf(",%s", value)
+					arg.Values = append(arg.Values, value)
+					isHeld = true
+				}
+			}
 
-				// check if a flag is an inverted flag
-				if ok, flagName := isInvertedFlag(value); ok {
-					if _, ok := commandConfig.Flags[flagName]; !ok {
-						return nil, ErrorUnknownFlag{value}
-					}
+			if !isHeld {
+				surplus = append(surplus, value)
+			}
+		}
+	}
 
-					flag = commandConfig.Flags[flagName]
-				} else {
+	// in the strict mode, values not held by any argument are rejected
+	if registry.StrictArgs && len(surplus) > 0 {
+		return nil, ErrorTooManyArguments{surplus}
+	}
 
-					// flag should not registered as an inverted flag
-					if _flag, ok := commandConfig.Flags[name]; !ok || _flag.IsInverted {
-						return nil, ErrorUnknownFlag{value}
-					}
+	// set values of the flags absent from the command-line arguments using environment variables
+	if err := registry.setFlagsFromEnv(commandConfig); err != nil {
+		return nil, err
+	}
 
-					flag = commandConfig.Flags[name]
-				}
+	// set values of the remaining flags using the loaded config files
+	if err := registry.setFlagsFromConfig(commandConfig, definition); err != nil {
+		return nil, err
+	}
+
+	// set values of the flags of the root command absent from the command-line arguments
+	if rootCommandConfig != commandConfig {
+		if err := registry.setFlagsFromEnv(rootCommandConfig); err != nil {
+			return nil, err
+		}
+
+		if err := registry.setFlagsFromConfig(rootCommandConfig, registry.Commands[""]); err != nil {
+			return nil, err
+		}
+	}
+
+	// check if all required flags and arguments are provided
+	if missing := commandConfig.missingRequired(); len(missing) > 0 {
+		return nil, ErrorMissingRequired{missing}
+	}
+
+	// check if the number of values of a variadic argument is within limits
+	for _, argName := range commandConfig.ArgNames {
+		arg := commandConfig.Args[argName]
+
+		if count := len(arg.Values); arg.IsVariadic && (count < arg.MinValues || (arg.MaxValues > 0 && count > arg.MaxValues)) {
+			return nil, ErrorArgumentCount{argName, count, arg.MinValues, arg.MaxValues}
+		}
+	}
+
+	return &ParseResult{definition, commandConfig.Flags, commandConfig.Args, rootCommandConfig.Flags}, nil
+}
+
+// setFlagsFromEnv sets values of the flags absent from the command-line arguments using the bound environment variables.
+// The first non-empty environment variable in the `EnvVars` of a flag is used.
+// If an environment variable value can not be converted to the type of the flag, it returns `ErrorInvalidValue` error.
+func (registry *Registry) setFlagsFromEnv(commandConfig *CommandConfig) error {
+
+	for _, flagName := range commandConfig.flagNames() {
+		flag := commandConfig.Flags[flagName]
+
+		// command-line arguments take precedence
+		if flag.IsSet {
+			continue
+		}
+
+		for _, envName := range flag.EnvVars {
+			name := registry.EnvPrefix + envName
+
+			value := os.Getenv(name)
+			if len(value) == 0 {
+				continue
 			}
 
-			// set flag value
+			// value of a boolean flag is normalized to "true" or "false"
 			if flag.IsBoolean {
-				if flag.IsInverted {
-					flag.Value = "false" // if flag is an inverted flag, its value will be `false`
-				} else {
-					flag.Value = "true"
+				v, ok := parseBoolLiteral(value)
+				if !ok {
+					return ErrorInvalidValue{"--" + flag.Name, value, TypeBool, SourceEnv}
 				}
+
+				value = strconv.FormatBool(v)
 			} else {
-				if nextValue, nextValuesToProcess := nextValue(valuesToProcess); len(nextValue) != 0 && !isFlag(nextValue) {
-					flag.Value = nextValue
-					valuesToProcess = nextValuesToProcess
+
+				// each comma-separated value of a repeatable flag is checked
+				values := []string{value}
+				if flag.IsRepeatable {
+					values = splitStringSlice(value)
+				}
+
+				for _, v := range values {
+					if !isValidValue(v, flag.Type) {
+						return ErrorInvalidValue{"--" + flag.Name, v, flag.Type, SourceEnv}
+					}
 				}
 			}
-		} else {
 
-			// process as argument
-			for index, argName := range commandConfig.ArgNames {
+			flag.Value = value
+			flag.IsSet, flag.Source = true, SourceEnv
 
-				// get argument object stored in the `commandConfig`
-				arg := commandConfig.Args[argName]
+			// comma-separated values of a repeatable flag
+			if flag.IsRepeatable && !flag.IsBoolean {
+				flag.Values = splitStringSlice(value)
+			}
 
-				// assign value if value of the argument is empty
-				if len(arg.Value) == 0 {
-					arg.Value = value
-					break
+			break
+		}
+	}
+
+	return nil
+}
+
+// parseFlag processes a flag (or a cluster of combined short flags) of the command and returns the remaining values.
+// The value of a flag which takes a value is taken from the remaining values (a negative number is a value, not a flag),
+// unless the value is attached to the flag.
+// The value of a flag with an optional value must be attached to the flag.
+func (registry *Registry) parseFlag(commandConfig *CommandConfig, value string, valuesToProcess []string) ([]string, error) {
+
+	// value attached to a flag (like `--dir=value`)
+	value, attachedValue, hasAttachedValue := splitAttachedValue(value)
+
+	// flags to process (a cluster of combined short flags like `-xvf` contains multiple flags)
+	flagValues := []string{value}
+
+	// value attached to a short flag (like `-ofile`)
+	if isShortFlagCluster(value) {
+		var err error
+		if flagValues, attachedValue, hasAttachedValue, err = splitShortFlagCluster(value, commandConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	for index, flagValue := range flagValues {
+
+		// the attached value belongs to the last flag
+		isAttached := hasAttachedValue && index == len(flagValues)-1
+
+		// long flag matched by a prefix
+		var err error
+		if flagValue, err = registry.expandFlag(commandConfig, flagValue); err != nil {
+			return nil, err
+		}
+
+		// built-in help flag
+		if isHelpFlag(flagValue, commandConfig) {
+			return nil, ErrorHelpRequested{commandConfig.Path, registry.Help(programName(), commandConfig)}
+		}
+
+		// get flag object stored in the `commandConfig`
+		flag, err := commandConfig.lookupFlag(flagValue)
+		if err != nil {
+			return nil, err
+		}
+
+		// count flag occurrences
+		flag.Count++
+
+		// set flag value
+		if flag.IsBoolean {
+			if flag.IsInverted {
+				flag.Value = "false" // if flag is an inverted flag, its value will be `false`
+			} else {
+				flag.Value = "true"
+			}
+
+			// a boolean literal attached to a boolean flag (like `--force=false` or `--no-clean=yes`)
+			if isAttached {
+				v, ok := parseBoolLiteral(attachedValue)
+				if !ok {
+					return nil, ErrorInvalidValue{"--" + flag.Name, attachedValue, TypeBool, SourceArgv}
 				}
 
-				// if last argument is a variadic argument, append values
-				if (index == len(commandConfig.ArgNames)-1) && arg.IsVariadic {
-					arg.Value += fmt.Sprintf(",%s", value)
+				if v == flag.IsInverted {
+					flag.Value = "false"
+				} else {
+					flag.Value = "true"
 				}
 			}
+		} else if isAttached {
+			flag.Value = attachedValue
+		} else if flag.IsValueOptional {
+			flag.Value = ""
+		} else if nextValue, nextValuesToProcess := nextValue(valuesToProcess); len(valuesToProcess) > 0 && (!isFlag(nextValue) || isNegativeNumber(nextValue)) {
+			flag.Value = nextValue
+			valuesToProcess = nextValuesToProcess
+		} else {
+			return nil, ErrorMissingFlagValue{flagValue}
+		}
+
+		flag.IsSet, flag.Source = true, SourceArgv
+
+		// check if flag value can be converted to the registered type
+		if len(flag.Value) != 0 && !isValidValue(flag.Value, flag.Type) {
+			return nil, ErrorInvalidValue{"--" + flag.Name, flag.Value, flag.Type, SourceArgv}
+		}
+
+		// collect values of a repeatable flag
+		if flag.IsRepeatable && !flag.IsBoolean {
+			flag.Values = append(flag.Values, flag.Value)
+		}
+	}
+
+	return valuesToProcess, nil
+}
+
+// inheritedFlags returns the persistent flags of the parent commands (the closest parent first)
+// and the root command inherited by the command.
+func (registry *Registry) inheritedFlags(commandConfig *CommandConfig) []*Flag {
+
+	parents := make([]*CommandConfig, 0)
+	for parent := commandConfig.parent; parent != nil; parent = parent.parent {
+		parents = append(parents, parent)
+	}
+
+	if rootCommandConfig, ok := registry.Commands[""]; ok && commandConfig != rootCommandConfig {
+		parents = append(parents, rootCommandConfig)
+	}
+
+	flags := make([]*Flag, 0)
+	for _, parent := range parents {
+		for _, flagName := range parent.flagNames() {
+			if flag := parent.Flags[flagName]; flag.IsPersistent {
+				flags = append(flags, flag)
+			}
+		}
+	}
+
+	return flags
+}
+
+// resolve returns a copy of the command (see `clone` method) including copies of the inherited persistent flags.
+// A flag of the command (or a closer parent) overrides an inherited flag with the same name.
+func (registry *Registry) resolve(commandConfig *CommandConfig) *CommandConfig {
+
+	clone := commandConfig.clone()
+
+	clone.flagsShort = make(map[string]string, len(commandConfig.flagsShort))
+	for shortName, flagName := range commandConfig.flagsShort {
+		clone.flagsShort[shortName] = flagName
+	}
+
+	for _, flag := range registry.inheritedFlags(commandConfig) {
+		if _, ok := clone.Flags[flag.Name]; ok {
+			continue
+		}
+
+		clone.Flags[flag.Name] = flag.clone()
+
+		if _, ok := clone.flagsShort[flag.ShortName]; len(flag.ShortName) != 0 && !ok {
+			clone.flagsShort[flag.ShortName] = flag.Name
+		}
+	}
+
+	return clone
+}
+
+// splitLeadingFlags returns the leading values which are flags (with their values) available to the command,
+// like `--verbose` in `--verbose add`, and the remaining values. If `isPersistentOnly` is true, only persistent flags are accepted.
+func (registry *Registry) splitLeadingFlags(values []string, commandConfig *CommandConfig, isPersistentOnly bool) (flagValues []string, rest []string) {
+
+	resolved := registry.resolve(commandConfig)
+
+	index := 0
+	for index < len(values) && isFlag(values[index]) && !isEndOfFlags(values[index]) {
+
+		// flags of a cluster of combined short flags (like `-xvf`)
+		value, _, hasAttachedValue := splitAttachedValue(values[index])
+		flagNames := []string{value}
+		if isShortFlagCluster(value) {
+			var err error
+			if flagNames, _, hasAttachedValue, err = splitShortFlagCluster(value, resolved); err != nil {
+				break
+			}
+		}
+
+		// the last flag of a cluster may take a value
+		var flag *Flag
+		for _, flagName := range flagNames {
+			var err error
+			if flagName, err = registry.expandFlag(resolved, flagName); err != nil {
+				flag = nil
+				break
+			}
+
+			if flag, err = resolved.lookupFlag(flagName); err != nil || (isPersistentOnly && !flag.IsPersistent) {
+				flag = nil
+				break
+			}
+		}
+
+		if flag == nil {
+			break
+		}
+
+		index++
+
+		// value of the flag
+		if !flag.IsBoolean && !flag.IsValueOptional && !hasAttachedValue && index < len(values) && (!isFlag(values[index]) || isNegativeNumber(values[index])) {
+			index++
 		}
 	}
 
-	return commandConfig, nil
+	return values[:index], values[index:]
 }
 
 // NewRegistry returns new instance of the "Registry"
-func NewRegistry() Registry {
-	return make(Registry)
+func NewRegistry() *Registry {
+	return &Registry{
+		Commands: make(map[string]*CommandConfig),
+	}
 }
 
 /*---------------------*/
 
+// newCommandConfig returns a new `CommandConfig` object registered under the `parent` command.
+func newCommandConfig(name string, parent *CommandConfig) *CommandConfig {
+
+	// full path of the command
+	path := name
+	if parent != nil {
+		path = strings.TrimSpace(parent.Path + " " + name)
+	}
+
+	return &CommandConfig{
+		Name:        name,
+		Path:        path,
+		Flags:       make(map[string]*Flag),
+		flagsShort:  make(map[string]string),
+		Args:        make(map[string]*Arg),
+		ArgNames:    make([]string, 0),
+		SubCommands: make(map[string]*CommandConfig),
+		parent:      parent,
+	}
+}
+
 // CommandConfig type holds the structure and values of the command-line arguments of command.
 type CommandConfig struct {
 
 	// name of the sub-command ("" for the root command)
 	Name string
 
+	// full path of the sub-command, such as "remote add" ("" for the root command)
+	Path string
+
+	// description of the command (displayed in the usage text)
+	Description string
+
+	// parent command (nil for a command registered with the registry)
+	parent *CommandConfig
+
+	// alternative names of the command (like "rm" for "remove")
+	Aliases []string
+
+	// registered child sub-commands
+	SubCommands map[string]*CommandConfig
+
 	// command-line flags
 	Flags map[string]*Flag
 
@@ -370,15 +990,190 @@ type CommandConfig struct {
 
 	// list of the argument names (for ordered iteration)
 	ArgNames []string
+
+	// function invoked by the `Registry.Execute` method when the command is matched
+	Handler HandlerFunc
+
+	// functions invoked before and after the handler of the command and its sub-commands
+	BeforeHooks []HandlerFunc
+	AfterHooks  []AfterHookFunc
+
+	// functions wrapping the run step of the command and its sub-commands
+	Middlewares []MiddlewareFunc
+}
+
+// ParseResult type holds the values of the flags and the arguments of a command returned by the `Registry.ParseArgs` method.
+// The flags and the arguments are copies owned by the result and they are not shared with the registry.
+type ParseResult struct {
+
+	// registered command matched by the command-line arguments
+	Command *CommandConfig
+
+	// flags of the command with values
+	Flags map[string]*Flag
+
+	// arguments of the command with values
+	Args map[string]*Arg
+
+	// flags of the root command with values (provided before the command name), same as `Flags` for the root command
+	RootFlags map[string]*Flag
+}
+
+// Register method registers a child sub-command of the command.
+// The "name" argument should be a simple string.
+// A child sub-command is matched by `Registry.Parse` when its name follows the name of the parent command,
+// for example `remote add` matches the `add` sub-command registered with the `remote` command.
+// The optional "aliases" are alternative names of the sub-command (like "rm" for "remove").
+// If a command is already registered, the registered `*CommandConfig` object is returned (with the new aliases added).
+// If the command is already registered, second return value will be `true`.
+// It panics if an alias (or the name) is already used by another command registered at the same level.
+func (commandConfig *CommandConfig) Register(name string, aliases ...string) (*CommandConfig, bool) {
+
+	// remove all whitespaces
+	commandName := removeWhitespaces(name)
+
+	// check if command is already registered, if found, return existing entry
+	if _commandConfig, ok := commandConfig.SubCommands[commandName]; ok {
+		_commandConfig.addAliases(commandConfig.SubCommands, aliases)
+		return _commandConfig, true
+	}
+
+	// construct new `CommandConfig` object
+	subCommandConfig := newCommandConfig(commandName, commandConfig)
+	subCommandConfig.checkName(commandConfig.SubCommands)
+	subCommandConfig.addAliases(commandConfig.SubCommands, aliases)
+
+	// add entry to the sub-commands of the command
+	commandConfig.SubCommands[commandName] = subCommandConfig
+
+	return subCommandConfig, false
+}
+
+// nextArg returns the argument which holds the next argument value (the first argument without a value
+// or the last variadic argument), or `nil` if all arguments hold a value.
+func (commandConfig *CommandConfig) nextArg() *Arg {
+
+	for index, argName := range commandConfig.ArgNames {
+		if arg := commandConfig.Args[argName]; !arg.IsSet || (index == len(commandConfig.ArgNames)-1 && arg.IsVariadic) {
+			return arg
+		}
+	}
+
+	return nil
+}
+
+// missingRequired returns the names of the required arguments and flags (with `--` prefix) without a value.
+func (commandConfig *CommandConfig) missingRequired() []string {
+
+	missing := make([]string, 0)
+
+	// required arguments (in registration order)
+	for _, argName := range commandConfig.ArgNames {
+		if arg := commandConfig.Args[argName]; arg.IsRequired && !arg.IsSet {
+			missing = append(missing, argName)
+		}
+	}
+
+	// required flags (in alphabetical order)
+	for _, flagName := range commandConfig.flagNames() {
+		if flag := commandConfig.Flags[flagName]; flag.IsRequired && !flag.IsSet {
+			missing = append(missing, "--"+flagName)
+		}
+	}
+
+	return missing
+}
+
+// clone returns a copy of the command with copies of its flags and arguments without values.
+func (commandConfig *CommandConfig) clone() *CommandConfig {
+
+	clone := *commandConfig
+
+	clone.Flags = make(map[string]*Flag, len(commandConfig.Flags))
+	for flagName, flag := range commandConfig.Flags {
+		clone.Flags[flagName] = flag.clone()
+	}
+
+	clone.Args = make(map[string]*Arg, len(commandConfig.Args))
+	for argName, arg := range commandConfig.Args {
+		argCopy := *arg
+		argCopy.Value, argCopy.Values = "", nil
+		argCopy.IsSet, argCopy.Source = false, SourceDefault
+		clone.Args[argName] = &argCopy
+	}
+
+	return &clone
+}
+
+// clone returns a copy of the flag without values.
+func (flag *Flag) clone() *Flag {
+
+	flagCopy := *flag
+	flagCopy.Value, flagCopy.Values, flagCopy.Count = "", nil, 0
+	flagCopy.IsSet, flagCopy.Source = false, SourceDefault
+
+	return &flagCopy
+}
+
+// flagNames returns the names of the registered flags in alphabetical order.
+func (commandConfig *CommandConfig) flagNames() []string {
+
+	flagNames := make([]string, 0, len(commandConfig.Flags))
+	for flagName := range commandConfig.Flags {
+		flagNames = append(flagNames, flagName)
+	}
+
+	sort.Strings(flagNames)
+
+	return flagNames
+}
+
+// lookupFlag returns the registered flag object for a short flag (`-f`), a long flag (`--flag`) or an inverted flag (`--no-flag`).
+// If the flag is not registered, it returns `ErrorUnknownFlag` error.
+func (commandConfig *CommandConfig) lookupFlag(value string) (*Flag, error) {
+
+	// trim `-` characters from the `value`
+	name := strings.TrimLeft(value, "-")
+
+	// check if flag is short or long
+	if isShortFlag(value) {
+		if _, ok := commandConfig.flagsShort[name]; !ok {
+			return nil, commandConfig.unknownFlag(value)
+		}
+
+		// get long flag name
+		flagName := commandConfig.flagsShort[name]
+
+		return commandConfig.Flags[flagName], nil
+	}
+
+	// check if a flag is an inverted flag
+	if ok, flagName := isInvertedFlag(value); ok {
+		if _, ok := commandConfig.Flags[flagName]; !ok {
+			return nil, commandConfig.unknownFlag(value)
+		}
+
+		return commandConfig.Flags[flagName], nil
+	}
+
+	// flag should not registered as an inverted flag
+	if _flag, ok := commandConfig.Flags[name]; !ok || _flag.IsInverted {
+		return nil, commandConfig.unknownFlag(value)
+	}
+
+	return commandConfig.Flags[name], nil
 }
 
 // AddArg registers an argument configuration with the command.
 // The `name` argument represents the name of the argument.
 // If value of the `name` argument ends with `...` suffix, then it is a variadic argument.
 // Variadic argument can accept multiple argument values and it should be the last registered argument.
-// Values of a variadic argument will be concatenated using comma (,).
+// Values of a variadic argument will be concatenated using comma (,) in the `Value` field and
+// the individual values are stored in the `Values` field.
+// The number of values of a variadic argument can be limited by setting `MinValues` and `MaxValues` fields of the returned `*Arg` object.
 // The `defaultValue` argument represents the default value of the argument.
 // All arguments without a default value must be registered first.
+// An argument can be marked as required by setting the `IsRequired` field of the returned `*Arg` object.
 // If an argument with given `name` is already registered, then argument registration is skipped
 // and registered `*Arg` object returned.
 // If the argument is already registered, second return value will be `true`.
@@ -426,6 +1221,14 @@ func (commandConfig *CommandConfig) AddArg(name string, defaultValue string) (*A
 // If the `name` value starts with `no-` prefix, then it is considered as an inverted flag.
 // An inverted flag is registered with the name `<flag>` produced by removing `no-` prefix from `no-<flag>` and its defaut value is "true".
 // When command-line arguments contain `--no-<flag>`, the value of the `<flag>` becomes "false".
+// A flag can be marked as required by setting the `IsRequired` field of the returned `*Flag` object.
+// A flag can be bound to environment variables by setting the `EnvVars` field of the returned `*Flag` object.
+// A flag can be inherited by the sub-commands by setting the `IsPersistent` field of the returned `*Flag` object.
+// A flag which takes a value can be marked as repeatable by setting the `IsRepeatable` field of the returned `*Flag` object.
+// The value of a flag which takes a value can be made optional by setting the `IsValueOptional` field of the returned `*Flag` object,
+// in which case the value must be attached to the flag (like `--color=always`) and the flag alone (like `--color`) sets an empty value.
+// The values of a repeatable flag (like `--tag a --tag b`) are collected in the `Values` field and
+// the `Value` field holds the last value. The `Count` field holds the number of occurrences of any flag (like `-vvv`).
 // If a flag with given `name` is already registered, then flag registration is skipped and registered `*Flag` object returned.
 // If the flag is already registered, second return value will be `true`.
 func (commandConfig *CommandConfig) AddFlag(name string, shortName string, isBool bool, defaultValue string) (*Flag, bool) {
@@ -482,6 +1285,38 @@ func (commandConfig *CommandConfig) AddFlag(name string, shortName string, isBoo
 	return flag, false
 }
 
+// AddTypedArg registers an argument configuration with the command like `AddArg` method,
+// but the value of the argument must be convertible to the `valueType` type.
+// For a variadic argument, each value must be convertible to the `valueType` type.
+// If an argument value can not be converted, `Registry.Parse` returns `ErrorInvalidValue` error.
+func (commandConfig *CommandConfig) AddTypedArg(name string, valueType ValueType, defaultValue string) (*Arg, bool) {
+
+	arg, ok := commandConfig.AddArg(name, defaultValue)
+
+	// set type of a newly registered argument
+	if !ok {
+		arg.Type = valueType
+	}
+
+	return arg, ok
+}
+
+// AddTypedFlag registers a command-line flag with the command like `AddFlag` method,
+// but the value of the flag must be convertible to the `valueType` type.
+// A flag of the `TypeBool` type is registered as a boolean flag.
+// If a flag value can not be converted, `Registry.Parse` returns `ErrorInvalidValue` error.
+func (commandConfig *CommandConfig) AddTypedFlag(name string, shortName string, valueType ValueType, defaultValue string) (*Flag, bool) {
+
+	flag, ok := commandConfig.AddFlag(name, shortName, valueType == TypeBool, defaultValue)
+
+	// set type of a newly registered flag
+	if !ok {
+		flag.Type = valueType
+	}
+
+	return flag, ok
+}
+
 /*---------------------*/
 
 // Flag type holds the structured information about a flag.
@@ -504,6 +1339,45 @@ type Flag struct {
 
 	// value of the flag (provided by the user)
 	Value string
+
+	// type of the value of the flag
+	Type ValueType
+
+	// if the flag must be provided in the command-line arguments
+	IsRequired bool
+
+	// if the flag can be provided multiple times to collect multiple values
+	IsRepeatable bool
+
+	// if the flag is inherited by the sub-commands (a persistent flag of the root command is inherited by all commands)
+	IsPersistent bool
+
+	// if the value of the flag is optional, it must be attached to the flag (like `--color=always`)
+	IsValueOptional bool
+
+	// values of a repeatable flag (in the order of occurrence)
+	Values []string
+
+	// number of occurrences of the flag in the command-line arguments
+	Count int
+
+	// description of the flag (displayed in the usage text)
+	Description string
+
+	// name of the value of the flag (displayed in the usage text as `--flag <value>`)
+	MetaVar string
+
+	// returns completion candidates of the value of the flag for the partially typed word
+	Complete func(word string) []string
+
+	// names of the environment variables (without the registry prefix) used when the flag is absent
+	EnvVars []string
+
+	// if the value of the flag is set (even to an empty string) by the command-line arguments, environment variables or config files
+	IsSet bool
+
+	// source of the effective value of the flag
+	Source ValueSource
 }
 
 /*---------------------*/
@@ -521,4 +1395,31 @@ type Arg struct {
 
 	// value of the argument (provided by the user)
 	Value string
+
+	// type of the value of the argument
+	Type ValueType
+
+	// if the argument must be provided in the command-line arguments
+	IsRequired bool
+
+	// individual values of the argument (provided by the user)
+	Values []string
+
+	// minimum number of values of a variadic argument
+	MinValues int
+
+	// maximum number of values of a variadic argument (0 for no limit)
+	MaxValues int
+
+	// description of the argument (displayed in the usage text)
+	Description string
+
+	// returns completion candidates of the value of the argument for the partially typed word
+	Complete func(word string) []string
+
+	// if the value of the argument is provided in the command-line arguments (even as an empty string)
+	IsSet bool
+
+	// source of the effective value of the argument
+	Source ValueSource
 }
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -17,7 +20,6 @@ func TestUnsupportedAssignment(t *testing.T) {
 	options := map[string][]string{
 		"---version": []string{"---version"},
 		"---v":       []string{"---v=1.0.0"},
-		"-version":   []string{"-version"},
 	}
 
 	for flag, options := range options {
@@ -46,11 +48,11 @@ func TestEmptyRootCommand(t *testing.T) {
 	} else {
 		lines := []string{
 			`sub-command => ""`,
-			`argument(output) => &clapper.Arg{Name:"output", IsVariadic:false, DefaultValue:"", Value:""}`,
-			`flag(force) => &clapper.Flag{Name:"force", ShortName:"f", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:""}`,
-			`flag(verbose) => &clapper.Flag{Name:"verbose", ShortName:"v", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:""}`,
-			`flag(version) => &clapper.Flag{Name:"version", ShortName:"V", IsBoolean:false, IsInverted:false, DefaultValue:"", Value:""}`,
-			`flag(dir) => &clapper.Flag{Name:"dir", ShortName:"", IsBoolean:false, IsInverted:false, DefaultValue:"/var/users", Value:""}`,
+			`argument(output) => &clapper.Arg{Name:"output", IsVariadic:false, DefaultValue:"", Value:"", Type:0, IsRequired:false, Values:[]string(nil), MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:false, Source:0}`,
+			`flag(force) => &clapper.Flag{Name:"force", ShortName:"f", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:0, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:false, Source:0}`,
+			`flag(verbose) => &clapper.Flag{Name:"verbose", ShortName:"v", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:0, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:false, Source:0}`,
+			`flag(version) => &clapper.Flag{Name:"version", ShortName:"V", IsBoolean:false, IsInverted:false, DefaultValue:"", Value:"", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:0, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:false, Source:0}`,
+			`flag(dir) => &clapper.Flag{Name:"dir", ShortName:"", IsBoolean:false, IsInverted:false, DefaultValue:"/var/users", Value:"", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:0, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:false, Source:0}`,
 		}
 
 		for _, line := range lines {
@@ -72,7 +74,7 @@ func TestUnregisteredRootCommand(t *testing.T) {
 		fmt.Println("Error:", err)
 	} else {
 		lines := []string{
-			`error => clapper.ErrorUnknownCommand{Name:""}`,
+			`error => clapper.ErrorUnknownCommand{Name:"", Suggestions:[]string(nil)}`,
 		}
 
 		for _, line := range lines {
@@ -86,11 +88,12 @@ func TestUnregisteredRootCommand(t *testing.T) {
 // test an unregistered flag
 func TestUnregisteredFlag(t *testing.T) {
 
-	// flags
+	// flags (with suggestions)
 	flags := map[string][]string{
-		"-d":          []string{"-V", "1.0.1", "-v", "--force", "-d", "./sub/dir"},
-		"--m":         []string{"-V", "1.0.1", "-v", "--force", "--m", "./sub/dir"},
-		"--directory": []string{"-V", "1.0.1", "-v", "--force", "--directory", "./sub/dir"},
+		`"-d", Suggestions:[]string(nil)`:              []string{"-V", "1.0.1", "-v", "--force", "-d", "./sub/dir"},
+		`"--m", Suggestions:[]string(nil)`:             []string{"-V", "1.0.1", "-v", "--force", "--m", "./sub/dir"},
+		`"--directory", Suggestions:[]string{"--dir"}`: []string{"-V", "1.0.1", "-v", "--force", "--directory", "./sub/dir"},
+		`"-e", Suggestions:[]string(nil)`:              []string{"-version"},
 	}
 
 	for flag, options := range flags {
@@ -101,7 +104,7 @@ func TestUnregisteredFlag(t *testing.T) {
 		if output, err := cmd.Output(); err != nil {
 			fmt.Println("Error:", err)
 		} else {
-			if !strings.Contains(fmt.Sprintf("%s", output), fmt.Sprintf(`error => clapper.ErrorUnknownFlag{Name:"%s"}`, flag)) {
+			if !strings.Contains(fmt.Sprintf("%s", output), fmt.Sprintf(`error => clapper.ErrorUnknownFlag{Name:%s}`, flag)) {
 				t.Fail()
 			}
 		}
@@ -114,8 +117,8 @@ func TestValidInvertFlagValues(t *testing.T) {
 
 	// options list
 	optionsList := [][]string{
-		[]string{"info", "student", "-V", "-v", "--output", "./opt/dir", "--no-clean"},
-		[]string{"info", "student", "--version", "--no-clean", "--output", "./opt/dir", "--verbose"},
+		[]string{"info", "student", "-v", "--output", "./opt/dir", "--no-clean"},
+		[]string{"info", "student", "--no-clean", "--output", "./opt/dir", "--verbose"},
 	}
 
 	for _, options := range optionsList {
@@ -128,13 +131,13 @@ func TestValidInvertFlagValues(t *testing.T) {
 		} else {
 			lines := []string{
 				`sub-command => "info"`,
-				`argument(category) => &clapper.Arg{Name:"category", IsVariadic:false, DefaultValue:"manager", Value:"student"}`,
-				`argument(username) => &clapper.Arg{Name:"username", IsVariadic:false, DefaultValue:"", Value:""}`,
-				`argument(subjects) => &clapper.Arg{Name:"subjects", IsVariadic:true, DefaultValue:"", Value:""}`,
-				`flag(version) => &clapper.Flag{Name:"version", ShortName:"V", IsBoolean:false, IsInverted:false, DefaultValue:"1.0.1", Value:""}`,
-				`flag(output) => &clapper.Flag{Name:"output", ShortName:"o", IsBoolean:false, IsInverted:false, DefaultValue:"./", Value:"./opt/dir"}`,
-				`flag(verbose) => &clapper.Flag{Name:"verbose", ShortName:"v", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"true"}`,
-				`flag(clean) => &clapper.Flag{Name:"clean", ShortName:"", IsBoolean:true, IsInverted:true, DefaultValue:"true", Value:"false"}`,
+				`argument(category) => &clapper.Arg{Name:"category", IsVariadic:false, DefaultValue:"manager", Value:"student", Type:0, IsRequired:false, Values:[]string{"student"}, MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:true, Source:3}`,
+				`argument(username) => &clapper.Arg{Name:"username", IsVariadic:false, DefaultValue:"", Value:"", Type:0, IsRequired:false, Values:[]string(nil), MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:false, Source:0}`,
+				`argument(subjects) => &clapper.Arg{Name:"subjects", IsVariadic:true, DefaultValue:"", Value:"", Type:0, IsRequired:false, Values:[]string(nil), MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:false, Source:0}`,
+				`flag(version) => &clapper.Flag{Name:"version", ShortName:"V", IsBoolean:false, IsInverted:false, DefaultValue:"1.0.1", Value:"", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:0, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:false, Source:0}`,
+				`flag(output) => &clapper.Flag{Name:"output", ShortName:"o", IsBoolean:false, IsInverted:false, DefaultValue:"./", Value:"./opt/dir", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+				`flag(verbose) => &clapper.Flag{Name:"verbose", ShortName:"v", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"true", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+				`flag(clean) => &clapper.Flag{Name:"clean", ShortName:"", IsBoolean:true, IsInverted:true, DefaultValue:"true", Value:"false", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
 			}
 
 			for _, line := range lines {
@@ -151,8 +154,8 @@ func TestErrorUnknownFlagForInvertFlags(t *testing.T) {
 
 	// options list
 	optionsList := map[string][]string{
-		"--clean":   []string{"info", "student", "-V", "-v", "--output", "./opt/dir", "--clean"},
-		"--no-dump": []string{"info", "student", "--version", "--no-dump", "--output", "./opt/dir", "--verbose"},
+		`"--clean", Suggestions:[]string{"--no-clean"}`: []string{"info", "student", "-v", "--output", "./opt/dir", "--clean"},
+		`"--no-dump", Suggestions:[]string(nil)`:        []string{"info", "student", "--no-dump", "--output", "./opt/dir", "--verbose"},
 	}
 
 	for flag, options := range optionsList {
@@ -163,7 +166,7 @@ func TestErrorUnknownFlagForInvertFlags(t *testing.T) {
 		if output, err := cmd.Output(); err != nil {
 			fmt.Println("Error:", err)
 		} else {
-			if !strings.Contains(fmt.Sprintf("%s", output), fmt.Sprintf(`error => clapper.ErrorUnknownFlag{Name:"%s"}`, flag)) {
+			if !strings.Contains(fmt.Sprintf("%s", output), fmt.Sprintf(`error => clapper.ErrorUnknownFlag{Name:%s}`, flag)) {
 				t.Fail()
 			}
 		}
@@ -189,12 +192,12 @@ func TestFlagAssignmentSyntax(t *testing.T) {
 		} else {
 			lines := []string{
 				`sub-command => "info"`,
-				`argument(category) => &clapper.Arg{Name:"category", IsVariadic:false, DefaultValue:"manager", Value:"student"}`,
-				`argument(username) => &clapper.Arg{Name:"username", IsVariadic:false, DefaultValue:"", Value:"thatisuday"}`,
-				`argument(subjects) => &clapper.Arg{Name:"subjects", IsVariadic:true, DefaultValue:"", Value:""}`,
-				`flag(version) => &clapper.Flag{Name:"version", ShortName:"V", IsBoolean:false, IsInverted:false, DefaultValue:"1.0.1", Value:"2.0.0"}`,
-				`flag(output) => &clapper.Flag{Name:"output", ShortName:"o", IsBoolean:false, IsInverted:false, DefaultValue:"./", Value:""}`,
-				`flag(verbose) => &clapper.Flag{Name:"verbose", ShortName:"v", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"true"}`,
+				`argument(category) => &clapper.Arg{Name:"category", IsVariadic:false, DefaultValue:"manager", Value:"student", Type:0, IsRequired:false, Values:[]string{"student"}, MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:true, Source:3}`,
+				`argument(username) => &clapper.Arg{Name:"username", IsVariadic:false, DefaultValue:"", Value:"thatisuday", Type:0, IsRequired:false, Values:[]string{"thatisuday"}, MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:true, Source:3}`,
+				`argument(subjects) => &clapper.Arg{Name:"subjects", IsVariadic:true, DefaultValue:"", Value:"", Type:0, IsRequired:false, Values:[]string(nil), MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:false, Source:0}`,
+				`flag(version) => &clapper.Flag{Name:"version", ShortName:"V", IsBoolean:false, IsInverted:false, DefaultValue:"1.0.1", Value:"2.0.0", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+				`flag(output) => &clapper.Flag{Name:"output", ShortName:"o", IsBoolean:false, IsInverted:false, DefaultValue:"./", Value:"", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:0, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:false, Source:0}`,
+				`flag(verbose) => &clapper.Flag{Name:"verbose", ShortName:"v", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"true", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
 			}
 
 			for _, line := range lines {
@@ -211,8 +214,8 @@ func TestValidVariadicArgumentValues(t *testing.T) {
 
 	// options list
 	optionsList := [][]string{
-		[]string{"info", "student", "thatisuday", "-V", "-v", "--output", "./opt/dir", "--no-clean", "math", "science", "physics"},
-		[]string{"info", "student", "--version", "--no-clean", "thatisuday", "--output", "./opt/dir", "math", "science", "--verbose", "physics"},
+		[]string{"info", "student", "thatisuday", "-v", "--output", "./opt/dir", "--no-clean", "math", "science", "physics"},
+		[]string{"info", "student", "--no-clean", "thatisuday", "--output", "./opt/dir", "math", "science", "--verbose", "physics"},
 	}
 
 	for _, options := range optionsList {
@@ -225,13 +228,124 @@ func TestValidVariadicArgumentValues(t *testing.T) {
 		} else {
 			lines := []string{
 				`sub-command => "info"`,
-				`argument(category) => &clapper.Arg{Name:"category", IsVariadic:false, DefaultValue:"manager", Value:"student"}`,
-				`argument(username) => &clapper.Arg{Name:"username", IsVariadic:false, DefaultValue:"", Value:"thatisuday"}`,
-				`argument(subjects) => &clapper.Arg{Name:"subjects", IsVariadic:true, DefaultValue:"", Value:"math,science,physics"}`,
-				`flag(version) => &clapper.Flag{Name:"version", ShortName:"V", IsBoolean:false, IsInverted:false, DefaultValue:"1.0.1", Value:""}`,
-				`flag(output) => &clapper.Flag{Name:"output", ShortName:"o", IsBoolean:false, IsInverted:false, DefaultValue:"./", Value:"./opt/dir"}`,
-				`flag(verbose) => &clapper.Flag{Name:"verbose", ShortName:"v", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"true"}`,
-				`flag(clean) => &clapper.Flag{Name:"clean", ShortName:"", IsBoolean:true, IsInverted:true, DefaultValue:"true", Value:"false"}`,
+				`argument(category) => &clapper.Arg{Name:"category", IsVariadic:false, DefaultValue:"manager", Value:"student", Type:0, IsRequired:false, Values:[]string{"student"}, MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:true, Source:3}`,
+				`argument(username) => &clapper.Arg{Name:"username", IsVariadic:false, DefaultValue:"", Value:"thatisuday", Type:0, IsRequired:false, Values:[]string{"thatisuday"}, MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:true, Source:3}`,
+				`argument(subjects) => &clapper.Arg{Name:"subjects", IsVariadic:true, DefaultValue:"", Value:"math,science,physics", Type:0, IsRequired:false, Values:[]string{"math", "science", "physics"}, MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:true, Source:3}`,
+				`flag(version) => &clapper.Flag{Name:"version", ShortName:"V", IsBoolean:false, IsInverted:false, DefaultValue:"1.0.1", Value:"", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:0, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:false, Source:0}`,
+				`flag(output) => &clapper.Flag{Name:"output", ShortName:"o", IsBoolean:false, IsInverted:false, DefaultValue:"./", Value:"./opt/dir", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+				`flag(verbose) => &clapper.Flag{Name:"verbose", ShortName:"v", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"true", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+				`flag(clean) => &clapper.Flag{Name:"clean", ShortName:"", IsBoolean:true, IsInverted:true, DefaultValue:"true", Value:"false", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+			}
+
+			for _, line := range lines {
+				if !strings.Contains(fmt.Sprintf("%s", output), line) {
+					t.Fail()
+				}
+			}
+		}
+	}
+}
+
+// test combined short flags
+func TestCombinedShortFlags(t *testing.T) {
+
+	// options list
+	optionsList := [][]string{
+		[]string{"-fvV", "1.0.1", "userinfo"},
+		[]string{"userinfo", "-vf", "-V", "1.0.1"},
+		[]string{"-vfV=1.0.1", "userinfo"},
+	}
+
+	for _, options := range optionsList {
+		// command
+		cmd := exec.Command("go", append([]string{"run", "demo/cmd.go"}, options...)...)
+
+		// get output
+		if output, err := cmd.Output(); err != nil {
+			fmt.Println("Error:", err)
+		} else {
+			lines := []string{
+				`sub-command => ""`,
+				`argument(output) => &clapper.Arg{Name:"output", IsVariadic:false, DefaultValue:"", Value:"userinfo", Type:0, IsRequired:false, Values:[]string{"userinfo"}, MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:true, Source:3}`,
+				`flag(force) => &clapper.Flag{Name:"force", ShortName:"f", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"true", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+				`flag(verbose) => &clapper.Flag{Name:"verbose", ShortName:"v", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"true", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+				`flag(version) => &clapper.Flag{Name:"version", ShortName:"V", IsBoolean:false, IsInverted:false, DefaultValue:"", Value:"1.0.1", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+			}
+
+			for _, line := range lines {
+				if !strings.Contains(fmt.Sprintf("%s", output), line) {
+					t.Fail()
+				}
+			}
+		}
+	}
+}
+
+// test attached short flag values
+func TestAttachedShortFlagValues(t *testing.T) {
+
+	// options list
+	optionsList := [][]string{
+		[]string{"info", "student", "-o./opt/dir", "-vV2.0.0"},
+		[]string{"info", "student", "-o=./opt/dir", "-vV=2.0.0"},
+		[]string{"info", "-vo./opt/dir", "student", "-V", "2.0.0"},
+	}
+
+	for _, options := range optionsList {
+		// command
+		cmd := exec.Command("go", append([]string{"run", "demo/cmd.go"}, options...)...)
+
+		// get output
+		if output, err := cmd.Output(); err != nil {
+			fmt.Println("Error:", err)
+		} else {
+			lines := []string{
+				`sub-command => "info"`,
+				`argument(category) => &clapper.Arg{Name:"category", IsVariadic:false, DefaultValue:"manager", Value:"student", Type:0, IsRequired:false, Values:[]string{"student"}, MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:true, Source:3}`,
+				`flag(version) => &clapper.Flag{Name:"version", ShortName:"V", IsBoolean:false, IsInverted:false, DefaultValue:"1.0.1", Value:"2.0.0", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+				`flag(output) => &clapper.Flag{Name:"output", ShortName:"o", IsBoolean:false, IsInverted:false, DefaultValue:"./", Value:"./opt/dir", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+				`flag(verbose) => &clapper.Flag{Name:"verbose", ShortName:"v", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"true", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+			}
+
+			for _, line := range lines {
+				if !strings.Contains(fmt.Sprintf("%s", output), line) {
+					t.Fail()
+				}
+			}
+		}
+	}
+}
+
+// test `--` terminator
+func TestEndOfFlagsTerminator(t *testing.T) {
+
+	// options list
+	optionsList := [][]string{
+		[]string{"info", "-v", "--", "-rf", "--output", "--no-clean"},
+		[]string{"info", "--verbose", "-rf", "--", "--output", "--no-clean"},
+	}
+
+	for index, options := range optionsList {
+		// command
+		cmd := exec.Command("go", append([]string{"run", "demo/cmd.go"}, options...)...)
+
+		// get output
+		if output, err := cmd.Output(); err != nil {
+			fmt.Println("Error:", err)
+		} else {
+			lines := []string{
+				`sub-command => "info"`,
+				`argument(category) => &clapper.Arg{Name:"category", IsVariadic:false, DefaultValue:"manager", Value:"-rf", Type:0, IsRequired:false, Values:[]string{"-rf"}, MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:true, Source:3}`,
+				`argument(username) => &clapper.Arg{Name:"username", IsVariadic:false, DefaultValue:"", Value:"--output", Type:0, IsRequired:false, Values:[]string{"--output"}, MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:true, Source:3}`,
+				`argument(subjects) => &clapper.Arg{Name:"subjects", IsVariadic:true, DefaultValue:"", Value:"--no-clean", Type:0, IsRequired:false, Values:[]string{"--no-clean"}, MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:true, Source:3}`,
+				`flag(output) => &clapper.Flag{Name:"output", ShortName:"o", IsBoolean:false, IsInverted:false, DefaultValue:"./", Value:"", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:0, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:false, Source:0}`,
+				`flag(verbose) => &clapper.Flag{Name:"verbose", ShortName:"v", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"true", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+				`flag(clean) => &clapper.Flag{Name:"clean", ShortName:"", IsBoolean:true, IsInverted:true, DefaultValue:"true", Value:"", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:0, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:false, Source:0}`,
+			}
+
+			// `-rf` before the terminator is an unknown flag
+			if index == 1 {
+				lines = []string{`error => clapper.ErrorUnknownFlag{Name:"-r", Suggestions:[]string(nil)}`}
 			}
 
 			for _, line := range lines {
@@ -266,11 +380,11 @@ func TestRootCommandWithOptions(t *testing.T) {
 		} else {
 			lines := []string{
 				`sub-command => ""`,
-				`argument(output) => &clapper.Arg{Name:"output", IsVariadic:false, DefaultValue:"", Value:"userinfo"}`,
-				`flag(force) => &clapper.Flag{Name:"force", ShortName:"f", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"true"}`,
-				`flag(verbose) => &clapper.Flag{Name:"verbose", ShortName:"v", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"true"}`,
-				`flag(version) => &clapper.Flag{Name:"version", ShortName:"V", IsBoolean:false, IsInverted:false, DefaultValue:"", Value:"1.0.1"}`,
-				`flag(dir) => &clapper.Flag{Name:"dir", ShortName:"", IsBoolean:false, IsInverted:false, DefaultValue:"/var/users", Value:"./sub/dir"}`,
+				`argument(output) => &clapper.Arg{Name:"output", IsVariadic:false, DefaultValue:"", Value:"userinfo", Type:0, IsRequired:false, Values:[]string{"userinfo"}, MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:true, Source:3}`,
+				`flag(force) => &clapper.Flag{Name:"force", ShortName:"f", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"true", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+				`flag(verbose) => &clapper.Flag{Name:"verbose", ShortName:"v", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"true", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+				`flag(version) => &clapper.Flag{Name:"version", ShortName:"V", IsBoolean:false, IsInverted:false, DefaultValue:"", Value:"1.0.1", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+				`flag(dir) => &clapper.Flag{Name:"dir", ShortName:"", IsBoolean:false, IsInverted:false, DefaultValue:"/var/users", Value:"./sub/dir", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
 			}
 
 			for _, line := range lines {
@@ -287,8 +401,8 @@ func TestSubCommandWithOptions(t *testing.T) {
 
 	// options list
 	optionsList := [][]string{
-		[]string{"info", "student", "-V", "-v", "--output", "./opt/dir"},
-		[]string{"info", "student", "--version", "--output", "./opt/dir", "--verbose"},
+		[]string{"info", "student", "-v", "--output", "./opt/dir"},
+		[]string{"info", "student", "--output", "./opt/dir", "--verbose"},
 	}
 
 	for _, options := range optionsList {
@@ -301,13 +415,13 @@ func TestSubCommandWithOptions(t *testing.T) {
 		} else {
 			lines := []string{
 				`sub-command => "info"`,
-				`argument(category) => &clapper.Arg{Name:"category", IsVariadic:false, DefaultValue:"manager", Value:"student"}`,
-				`argument(username) => &clapper.Arg{Name:"username", IsVariadic:false, DefaultValue:"", Value:""}`,
-				`argument(subjects) => &clapper.Arg{Name:"subjects", IsVariadic:true, DefaultValue:"", Value:""}`,
-				`flag(version) => &clapper.Flag{Name:"version", ShortName:"V", IsBoolean:false, IsInverted:false, DefaultValue:"1.0.1", Value:""}`,
-				`flag(output) => &clapper.Flag{Name:"output", ShortName:"o", IsBoolean:false, IsInverted:false, DefaultValue:"./", Value:"./opt/dir"}`,
-				`flag(verbose) => &clapper.Flag{Name:"verbose", ShortName:"v", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"true"}`,
-				`flag(clean) => &clapper.Flag{Name:"clean", ShortName:"", IsBoolean:true, IsInverted:true, DefaultValue:"true", Value:""}`,
+				`argument(category) => &clapper.Arg{Name:"category", IsVariadic:false, DefaultValue:"manager", Value:"student", Type:0, IsRequired:false, Values:[]string{"student"}, MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:true, Source:3}`,
+				`argument(username) => &clapper.Arg{Name:"username", IsVariadic:false, DefaultValue:"", Value:"", Type:0, IsRequired:false, Values:[]string(nil), MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:false, Source:0}`,
+				`argument(subjects) => &clapper.Arg{Name:"subjects", IsVariadic:true, DefaultValue:"", Value:"", Type:0, IsRequired:false, Values:[]string(nil), MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:false, Source:0}`,
+				`flag(version) => &clapper.Flag{Name:"version", ShortName:"V", IsBoolean:false, IsInverted:false, DefaultValue:"1.0.1", Value:"", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:0, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:false, Source:0}`,
+				`flag(output) => &clapper.Flag{Name:"output", ShortName:"o", IsBoolean:false, IsInverted:false, DefaultValue:"./", Value:"./opt/dir", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+				`flag(verbose) => &clapper.Flag{Name:"verbose", ShortName:"v", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"true", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+				`flag(clean) => &clapper.Flag{Name:"clean", ShortName:"", IsBoolean:true, IsInverted:true, DefaultValue:"true", Value:"", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:0, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:false, Source:0}`,
 			}
 
 			for _, line := range lines {
@@ -338,12 +452,12 @@ func TestSubCommandWithArguments(t *testing.T) {
 		} else {
 			lines := []string{
 				`sub-command => "info"`,
-				`argument(category) => &clapper.Arg{Name:"category", IsVariadic:false, DefaultValue:"manager", Value:"student"}`,
-				`argument(username) => &clapper.Arg{Name:"username", IsVariadic:false, DefaultValue:"", Value:"thatisuday"}`,
-				`argument(subjects) => &clapper.Arg{Name:"subjects", IsVariadic:true, DefaultValue:"", Value:""}`,
-				`flag(version) => &clapper.Flag{Name:"version", ShortName:"V", IsBoolean:false, IsInverted:false, DefaultValue:"1.0.1", Value:"2.0.0"}`,
-				`flag(output) => &clapper.Flag{Name:"output", ShortName:"o", IsBoolean:false, IsInverted:false, DefaultValue:"./", Value:""}`,
-				`flag(verbose) => &clapper.Flag{Name:"verbose", ShortName:"v", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"true"}`,
+				`argument(category) => &clapper.Arg{Name:"category", IsVariadic:false, DefaultValue:"manager", Value:"student", Type:0, IsRequired:false, Values:[]string{"student"}, MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:true, Source:3}`,
+				`argument(username) => &clapper.Arg{Name:"username", IsVariadic:false, DefaultValue:"", Value:"thatisuday", Type:0, IsRequired:false, Values:[]string{"thatisuday"}, MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:true, Source:3}`,
+				`argument(subjects) => &clapper.Arg{Name:"subjects", IsVariadic:true, DefaultValue:"", Value:"", Type:0, IsRequired:false, Values:[]string(nil), MinValues:0, MaxValues:0, Description:"", Complete:(func(string) []string)(nil), IsSet:false, Source:0}`,
+				`flag(version) => &clapper.Flag{Name:"version", ShortName:"V", IsBoolean:false, IsInverted:false, DefaultValue:"1.0.1", Value:"2.0.0", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
+				`flag(output) => &clapper.Flag{Name:"output", ShortName:"o", IsBoolean:false, IsInverted:false, DefaultValue:"./", Value:"", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:0, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:false, Source:0}`,
+				`flag(verbose) => &clapper.Flag{Name:"verbose", ShortName:"v", IsBoolean:true, IsInverted:false, DefaultValue:"false", Value:"true", Type:0, IsRequired:false, IsRepeatable:false, IsPersistent:false, IsValueOptional:false, Values:[]string(nil), Count:1, Description:"", MetaVar:"", Complete:(func(string) []string)(nil), EnvVars:[]string(nil), IsSet:true, Source:3}`,
 			}
 
 			for _, line := range lines {
@@ -354,3 +468,735 @@ func TestSubCommandWithArguments(t *testing.T) {
 		}
 	}
 }
+
+/*-------------------*/
+
+// test nested sub-commands
+func TestNestedSubCommands(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	remoteCommand, _ := registry.Register("remote")
+	remoteCommand.AddFlag("verbose", "v", true, "")
+
+	addCommand, _ := remoteCommand.Register("add")
+	addCommand.AddArg("name", "")
+	addCommand.AddArg("url", "")
+	addCommand.AddFlag("fetch", "f", true, "")
+
+	originCommand, _ := addCommand.Register("origin")
+	originCommand.AddArg("url", "")
+
+	// re-registration returns the existing command
+	if _addCommand, ok := remoteCommand.Register("add"); !ok || _addCommand != addCommand {
+		t.Errorf("expected existing sub-command to be returned")
+	}
+
+	// deepest matching command with arguments
+	command, err := registry.Parse([]string{"remote", "add", "upstream", "-f", "https://example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if command != addCommand || command.Path != "remote add" {
+		t.Errorf("expected `remote add` command, got %#v", command.Path)
+	}
+
+	if command.Args["name"].Value != "upstream" || command.Args["url"].Value != "https://example.com" || command.Flags["fetch"].Value != "true" {
+		t.Errorf("unexpected values: name=%#v url=%#v fetch=%#v", command.Args["name"].Value, command.Args["url"].Value, command.Flags["fetch"].Value)
+	}
+
+	// three levels of sub-commands
+	if command, err := registry.Parse([]string{"remote", "add", "origin", "https://example.com"}); err != nil || command != originCommand || command.Path != "remote add origin" {
+		t.Errorf("expected `remote add origin` command, got %#v (%v)", command, err)
+	}
+
+	// a parent command is matched when no child command name follows
+	if command, err := registry.Parse([]string{"remote", "-v"}); err != nil || command != remoteCommand {
+		t.Errorf("expected `remote` command, got %#v (%v)", command, err)
+	}
+
+	// unknown child command when the parent command does not accept arguments
+	if _, err := registry.Parse([]string{"remote", "rename"}); !reflect.DeepEqual(err, ErrorUnknownCommand{"rename", nil}) {
+		t.Errorf("expected unknown command error, got %#v", err)
+	}
+}
+
+// test typed flag and argument values
+func TestTypedValues(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	rootCommand, _ := registry.Register("")
+	rootCommand.AddTypedArg("count", TypeInt, "1")
+	rootCommand.AddTypedArg("ratios...", TypeFloat64, "")
+	rootCommand.AddTypedFlag("port", "p", TypeUint, "8080")
+	rootCommand.AddTypedFlag("timeout", "t", TypeDuration, "30s")
+	rootCommand.AddTypedFlag("debug", "d", TypeBool, "")
+	rootCommand.AddTypedFlag("tags", "", TypeStringSlice, "")
+
+	command, err := registry.Parse([]string{"3", "0.5", "1.5", "-p", "9090", "-d", "--tags=a,b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if v, err := command.Args["count"].Int(); err != nil || v != 3 {
+		t.Errorf("expected count 3, got %v (%v)", v, err)
+	}
+
+	if v, err := command.Flags["port"].Uint(); err != nil || v != 9090 {
+		t.Errorf("expected port 9090, got %v (%v)", v, err)
+	}
+
+	if v, err := command.Flags["timeout"].Duration(); err != nil || v.Seconds() != 30 {
+		t.Errorf("expected default timeout 30s, got %v (%v)", v, err)
+	}
+
+	if v, err := command.Flags["debug"].Bool(); err != nil || !v || !command.Flags["debug"].IsBoolean {
+		t.Errorf("expected debug true, got %v (%v)", v, err)
+	}
+
+	if v := command.Flags["tags"].StringSlice(); len(v) != 2 || v[0] != "a" || v[1] != "b" {
+		t.Errorf("expected tags [a b], got %#v", v)
+	}
+
+	// negative numbers are values of numeric arguments
+	result, err := registry.ParseArgs([]string{"-3", "-0.5", "-p", "9090", "-1.5"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Args["count"].Value != "-3" || !reflect.DeepEqual(result.Args["ratios"].Values, []string{"-0.5", "-1.5"}) || result.Flags["port"].Value != "9090" {
+		t.Errorf("expected count -3 and ratios [-0.5 -1.5], got %#v", result)
+	}
+
+	// invalid values
+	errs := map[string]error{
+		"-p=port":      ErrorInvalidValue{"--port", "port", TypeUint, SourceArgv},
+		"--timeout=10": ErrorInvalidValue{"--timeout", "10", TypeDuration, SourceArgv},
+		"three":        ErrorInvalidValue{"count", "three", TypeInt, SourceArgv},
+		"3 0.5 half":   ErrorInvalidValue{"ratios", "half", TypeFloat64, SourceArgv},
+	}
+
+	for values, expected := range errs {
+		for _, arg := range command.Args {
+			arg.Value = ""
+		}
+
+		if _, err := registry.Parse(strings.Split(values, " ")); err != expected {
+			t.Errorf("expected %#v for %#v, got %#v", expected, values, err)
+		}
+	}
+}
+
+// test required flags and arguments
+func TestRequiredValues(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	deployCommand, _ := registry.Register("deploy")
+	environmentArg, _ := deployCommand.AddArg("environment", "")
+	environmentArg.IsRequired = true
+	deployCommand.AddArg("region", "us-east-1")
+	tokenFlag, _ := deployCommand.AddFlag("token", "t", false, "")
+	tokenFlag.IsRequired = true
+	userFlag, _ := deployCommand.AddFlag("user", "u", false, "")
+	userFlag.IsRequired = true
+
+	// missing required values
+	_, err := registry.Parse([]string{"deploy", "--user", "admin"})
+	if e, ok := err.(ErrorMissingRequired); !ok || strings.Join(e.Names, " ") != "environment --token" {
+		t.Errorf("expected missing required error, got %#v", err)
+	}
+
+	// all required values are provided
+	if _, err := registry.Parse([]string{"deploy", "production", "-t", "secret", "-u", "admin"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// test flags without a value
+func TestMissingFlagValue(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	listCommand, _ := registry.Register("list")
+	listCommand.AddArg("path", "")
+	listCommand.AddFlag("output", "o", false, "./")
+	listCommand.AddFlag("verbose", "v", true, "")
+	colorFlag, _ := listCommand.AddFlag("color", "c", false, "auto")
+	colorFlag.IsValueOptional = true
+	colorFlag.MetaVar = "when"
+	listCommand.AddTypedFlag("offset", "", TypeInt, "0")
+
+	errs := []struct {
+		values   []string
+		expected error
+	}{
+		{[]string{"list", "--output"}, ErrorMissingFlagValue{"--output"}},
+		{[]string{"list", "-o", "-v", "./src"}, ErrorMissingFlagValue{"-o"}},
+		{[]string{"list", "./src", "-vo"}, ErrorMissingFlagValue{"-o"}},
+		{[]string{"list", "--output", "--", "./src"}, ErrorMissingFlagValue{"--output"}},
+	}
+
+	for _, e := range errs {
+		if _, err := registry.ParseArgs(e.values); err != e.expected {
+			t.Errorf("expected %#v, got %#v", e.expected, err)
+		}
+	}
+
+	// a negative number is a value
+	if result, err := registry.ParseArgs([]string{"list", "--offset", "-1", "-o", "-1.5", "./src"}); err != nil || result.Flags["offset"].Value != "-1" || result.Flags["output"].Value != "-1.5" || result.Args["path"].Value != "./src" {
+		t.Errorf("expected offset -1 and output -1.5, got %#v (%v)", result, err)
+	}
+
+	// an empty value is a value
+	if result, err := registry.ParseArgs([]string{"list", "--output="}); err != nil || !result.Flags["output"].IsSet || result.Flags["output"].Value != "" {
+		t.Errorf("expected empty output value, got %#v (%v)", result, err)
+	}
+
+	// the value of a flag with an optional value must be attached to the flag
+	values := map[string]string{
+		"list --color ./src":          "",
+		"list ./src -c":               "",
+		"list --color=always ./src":   "always",
+		"list -c=never ./src":         "never",
+		"list -vcalways ./src":        "always",
+		"list ./src --color= --color": "",
+	}
+
+	for value, expected := range values {
+		result, err := registry.ParseArgs(strings.Split(value, " "))
+		if err != nil {
+			t.Errorf("unexpected error for %#v: %v", value, err)
+			continue
+		}
+
+		if flag := result.Flags["color"]; !flag.IsSet || flag.Value != expected || result.Args["path"].Value != "./src" {
+			t.Errorf("expected color %#v for %#v, got %#v", expected, value, flag)
+		}
+	}
+
+	if result, err := registry.ParseArgs([]string{"list"}); err != nil || result.Flags["color"].IsSet || result.Flags["color"].Effective() != "auto" {
+		t.Errorf("expected default color, got %#v (%v)", result, err)
+	}
+
+	if usage := registry.Help("tool", listCommand); !strings.Contains(usage, "-c, --color[=<when>]") {
+		t.Errorf("expected optional value in the usage text, got %s", usage)
+	}
+
+	if message := (ErrorMissingFlagValue{"--output"}).Error(); message != "no value of flag --output found in the arguments" {
+		t.Errorf("unexpected error message: %s", message)
+	}
+}
+
+// test values attached to flags with `=`
+func TestAttachedFlagValues(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	rootCommand, _ := registry.Register("")
+	rootCommand.AddFlag("verbose", "v", true, "")
+
+	buildCommand, _ := registry.Register("build")
+	buildCommand.AddArg("target", "")
+	buildCommand.AddFlag("define", "D", false, "")
+	buildCommand.AddFlag("force", "f", true, "")
+	buildCommand.AddFlag("no-cache", "", true, "")
+
+	// values are split from the flags by the first `=`
+	result, err := registry.ParseArgs([]string{"--verbose=false", "build", "--define=a=b", "--force=false", "app", "--no-cache=no"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if flag := result.Flags["define"]; flag.Value != "a=b" {
+		t.Errorf("expected define a=b, got %#v", flag.Value)
+	}
+
+	if flag := result.Flags["force"]; flag.Value != "false" || !flag.IsSet || flag.Count != 1 {
+		t.Errorf("expected force false, got %#v", flag)
+	}
+
+	if flag := result.Flags["cache"]; flag.Value != "true" || !flag.IsSet {
+		t.Errorf("expected cache true, got %#v", flag)
+	}
+
+	if flag := result.RootFlags["verbose"]; flag.Value != "false" || !flag.IsSet {
+		t.Errorf("expected verbose false, got %#v", flag)
+	}
+
+	if arg := result.Args["target"]; arg.Value != "app" {
+		t.Errorf("expected target app, got %#v", arg.Value)
+	}
+
+	// boolean literals
+	literals := map[string]string{
+		"--force=true":    "true",
+		"--force=YES":     "true",
+		"--force=1":       "true",
+		"--force=No":      "false",
+		"--force=0":       "false",
+		"-f=false":        "false",
+		"--no-cache=true": "false",
+	}
+
+	for value, expected := range literals {
+		result, err := registry.ParseArgs([]string{"build", value, "-D=x=y"})
+		if err != nil {
+			t.Errorf("unexpected error for %#v: %v", value, err)
+			continue
+		}
+
+		flagName := "force"
+		if strings.HasPrefix(value, "--no-") {
+			flagName = "cache"
+		}
+
+		if flag := result.Flags[flagName]; flag.Value != expected || result.Flags["define"].Value != "x=y" {
+			t.Errorf("expected %s for %#v, got %#v", expected, value, flag)
+		}
+	}
+
+	// invalid boolean literals
+	errs := []struct {
+		values   []string
+		expected error
+	}{
+		{[]string{"build", "--force=maybe"}, ErrorInvalidValue{"--force", "maybe", TypeBool, SourceArgv}},
+		{[]string{"build", "--force="}, ErrorInvalidValue{"--force", "", TypeBool, SourceArgv}},
+		{[]string{"-v=on", "build"}, ErrorInvalidValue{"--verbose", "on", TypeBool, SourceArgv}},
+	}
+
+	for _, e := range errs {
+		if _, err := registry.ParseArgs(e.values); err != e.expected {
+			t.Errorf("expected %#v, got %#v", e.expected, err)
+		}
+	}
+
+	// boolean literals in environment variables and config files are normalized
+	registry.Commands["build"].Flags["force"].EnvVars = []string{"CLAPPER_TEST_FORCE"}
+
+	os.Setenv("CLAPPER_TEST_FORCE", "Yes")
+	defer os.Unsetenv("CLAPPER_TEST_FORCE")
+
+	if err := registry.LoadConfig(strings.NewReader("[build]\ncache = \"no\"\n"), ConfigTOML); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result, err := registry.ParseArgs([]string{"build"}); err != nil || result.Flags["force"].Value != "true" || result.Flags["cache"].Value != "false" {
+		t.Errorf("expected force true and cache false, got %#v (%v)", result, err)
+	}
+
+	os.Setenv("CLAPPER_TEST_FORCE", "on")
+
+	if _, err := registry.ParseArgs([]string{"build"}); err != (ErrorInvalidValue{"--force", "on", TypeBool, SourceEnv}) {
+		t.Errorf("expected invalid value error, got %#v", err)
+	}
+}
+
+// test repeatable flags and flag occurrence counts
+func TestRepeatableFlags(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	rootCommand, _ := registry.Register("")
+	rootCommand.AddFlag("verbose", "v", true, "")
+	rootCommand.AddFlag("output", "o", false, "")
+	tagFlag, _ := rootCommand.AddFlag("tag", "t", false, "")
+	tagFlag.IsRepeatable = true
+
+	command, err := registry.Parse([]string{"-vvv", "--tag", "a", "-o", "x", "-tb", "--output=y", "--tag=c"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if flag := command.Flags["verbose"]; flag.Count != 3 || flag.Value != "true" {
+		t.Errorf("expected verbose count 3, got %d", flag.Count)
+	}
+
+	if flag := command.Flags["tag"]; flag.Count != 3 || flag.Value != "c" || strings.Join(flag.Values, ",") != "a,b,c" {
+		t.Errorf("expected tag values [a b c], got %#v", flag.Values)
+	}
+
+	// a non-repeatable flag holds the last value
+	if flag := command.Flags["output"]; flag.Count != 2 || flag.Value != "y" || flag.Values != nil {
+		t.Errorf("expected output value y, got %#v", flag)
+	}
+}
+
+// test variadic argument values and limits
+func TestVariadicArgumentValues(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	copyCommand, _ := registry.Register("copy")
+	copyCommand.AddArg("destination", "")
+	filesArg, _ := copyCommand.AddArg("files...", "")
+	filesArg.MinValues = 1
+	filesArg.MaxValues = 3
+
+	command, err := registry.Parse([]string{"copy", "./out", "a,b.txt", "c.txt"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if arg := command.Args["files"]; len(arg.Values) != 2 || arg.Values[0] != "a,b.txt" || arg.Values[1] != "c.txt" || arg.Value != "a,b.txt,c.txt" {
+		t.Errorf("expected files [a,b.txt c.txt], got %#v", arg.Values)
+	}
+
+	if values := command.Args["files"].StringSlice(); len(values) != 2 || values[0] != "a,b.txt" {
+		t.Errorf("expected files [a,b.txt c.txt], got %#v", values)
+	}
+
+	// number of values out of limits
+	errs := map[string]error{
+		"copy ./out":                         ErrorArgumentCount{"files", 0, 1, 3},
+		"copy ./out a.txt b.txt c.txt d.txt": ErrorArgumentCount{"files", 4, 1, 3},
+	}
+
+	for values, expected := range errs {
+		for _, arg := range copyCommand.Args {
+			arg.Value, arg.Values = "", nil
+		}
+
+		if _, err := registry.Parse(strings.Split(values, " ")); err != expected {
+			t.Errorf("expected %#v for %#v, got %#v", expected, values, err)
+		}
+	}
+}
+
+// test surplus argument values in the strict mode
+func TestStrictArgs(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	infoCommand, _ := registry.Register("info")
+	infoCommand.AddArg("category", "manager")
+	infoCommand.AddArg("username", "")
+	infoCommand.AddFlag("verbose", "v", true, "")
+
+	copyCommand, _ := registry.Register("copy")
+	copyCommand.AddArg("destination", "")
+	copyCommand.AddArg("files...", "")
+
+	registry.Register("ghost")
+
+	values := []string{"info", "manager", "bob", "-v", "extra", "--", "-x"}
+
+	// surplus values are discarded by default
+	if result, err := registry.ParseArgs(values); err != nil || result.Args["username"].Value != "bob" {
+		t.Errorf("expected username bob, got %#v (%v)", result, err)
+	}
+
+	registry.StrictArgs = true
+
+	errs := []struct {
+		values   []string
+		expected error
+	}{
+		{values, ErrorTooManyArguments{[]string{"extra", "-x"}}},
+		{[]string{"ghost", "thatisuday"}, ErrorTooManyArguments{[]string{"thatisuday"}}},
+	}
+
+	for _, e := range errs {
+		if _, err := registry.ParseArgs(e.values); !reflect.DeepEqual(err, e.expected) {
+			t.Errorf("expected %#v, got %#v", e.expected, err)
+		}
+	}
+
+	// values held by the arguments (including a variadic argument)
+	for _, values := range [][]string{{"info", "manager", "bob", "-v"}, {"copy", "./out", "a.txt", "b.txt", "c.txt"}, {"ghost"}} {
+		if _, err := registry.ParseArgs(values); err != nil {
+			t.Errorf("unexpected error for %#v: %v", values, err)
+		}
+	}
+
+	if message := (ErrorTooManyArguments{[]string{"extra", "-x"}}).Error(); message != "too many arguments, unexpected values extra, -x found in the arguments" {
+		t.Errorf("unexpected error message: %s", message)
+	}
+}
+
+// test environment variable values of flags
+func TestEnvFlagValues(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+	registry.EnvPrefix = "CLAPPER_TEST_"
+
+	rootCommand, _ := registry.Register("")
+	portFlag, _ := rootCommand.AddTypedFlag("port", "p", TypeInt, "8080")
+	portFlag.EnvVars = []string{"PORT", "HTTP_PORT"}
+	debugFlag, _ := rootCommand.AddFlag("debug", "d", true, "")
+	debugFlag.EnvVars = []string{"DEBUG"}
+	hostFlag, _ := rootCommand.AddFlag("host", "", false, "localhost")
+	hostFlag.EnvVars = []string{"HOST"}
+
+	os.Setenv("CLAPPER_TEST_HTTP_PORT", "9090")
+	os.Setenv("CLAPPER_TEST_DEBUG", "1")
+	os.Setenv("CLAPPER_TEST_HOST", "example.com")
+	defer os.Unsetenv("CLAPPER_TEST_HTTP_PORT")
+	defer os.Unsetenv("CLAPPER_TEST_DEBUG")
+	defer os.Unsetenv("CLAPPER_TEST_HOST")
+
+	// command-line arguments take precedence over environment variables
+	command, err := registry.Parse([]string{"--host", "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if port, _ := command.Flags["port"].Int(); port != 9090 {
+		t.Errorf("expected port 9090 from environment, got %d", port)
+	}
+
+	if command.Flags["debug"].Value != "true" || command.Flags["host"].Value != "127.0.0.1" {
+		t.Errorf("unexpected values: debug=%#v host=%#v", command.Flags["debug"].Value, command.Flags["host"].Value)
+	}
+
+	// invalid environment variable value
+	os.Setenv("CLAPPER_TEST_DEBUG", "maybe")
+
+	if _, err := registry.Parse([]string{}); err != (ErrorInvalidValue{"--debug", "maybe", TypeBool, SourceEnv}) {
+		t.Errorf("expected invalid value error, got %#v", err)
+	}
+
+	os.Unsetenv("CLAPPER_TEST_DEBUG")
+
+	// comma-separated values of a repeatable typed flag are checked individually
+	portsFlag, _ := rootCommand.AddTypedFlag("ports", "", TypeUint, "")
+	portsFlag.IsRepeatable = true
+	portsFlag.EnvVars = []string{"PORTS"}
+
+	os.Setenv("CLAPPER_TEST_PORTS", "80,443")
+	defer os.Unsetenv("CLAPPER_TEST_PORTS")
+
+	if result, err := registry.ParseArgs([]string{}); err != nil || !reflect.DeepEqual(result.Flags["ports"].Values, []string{"80", "443"}) {
+		t.Errorf("expected ports [80 443] from environment, got %#v (%v)", result, err)
+	}
+
+	os.Setenv("CLAPPER_TEST_PORTS", "80,https")
+
+	if _, err := registry.ParseArgs([]string{}); err != (ErrorInvalidValue{"--ports", "https", TypeUint, SourceEnv}) {
+		t.Errorf("expected invalid value error, got %#v", err)
+	}
+}
+
+// test sources of the effective values
+func TestValueSources(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+	registry.EnvPrefix = "CLAPPER_SOURCE_"
+
+	rootCommand, _ := registry.Register("")
+	rootCommand.AddArg("input", "in.txt")
+	rootCommand.AddArg("output", "out.txt")
+	rootCommand.AddFlag("dir", "d", false, "/tmp")
+	hostFlag, _ := rootCommand.AddFlag("host", "", false, "localhost")
+	hostFlag.EnvVars = []string{"HOST"}
+	rootCommand.AddFlag("port", "", false, "8080")
+	rootCommand.AddFlag("user", "", false, "admin")
+
+	if err := registry.LoadConfig(strings.NewReader(`{"port": 9090, "host": "config.com"}`), ConfigJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	os.Setenv("CLAPPER_SOURCE_HOST", "env.com")
+	defer os.Unsetenv("CLAPPER_SOURCE_HOST")
+
+	// empty values are set explicitly
+	command, err := registry.Parse([]string{"", "--dir="})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string][3]string{
+		"dir":  {"true", "argv", ""},
+		"host": {"true", "env", "env.com"},
+		"port": {"true", "config", "9090"},
+		"user": {"false", "default", "admin"},
+	}
+
+	for flagName, values := range expected {
+		flag := command.Flags[flagName]
+		if actual := [3]string{strconv.FormatBool(flag.IsSet), flag.Source.String(), flag.Effective()}; actual != values {
+			t.Errorf("expected %v for flag %s, got %v", values, flagName, actual)
+		}
+	}
+
+	if arg := command.Args["input"]; !arg.IsSet || arg.Source != SourceArgv || arg.Effective() != "" {
+		t.Errorf("expected empty input from argv, got %#v", arg)
+	}
+
+	if arg := command.Args["output"]; arg.IsSet || arg.Source != SourceDefault || arg.Effective() != "out.txt" {
+		t.Errorf("expected default output, got %#v", arg)
+	}
+
+	// values of the previous parse are cleared
+	command, _ = registry.Parse([]string{})
+
+	if flag := command.Flags["dir"]; flag.IsSet || flag.Effective() != "/tmp" {
+		t.Errorf("expected default dir, got %#v", flag)
+	}
+}
+
+// test parse results are independent of the registry
+func TestParseResult(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	rootCommand, _ := registry.Register("")
+	rootCommand.AddArg("name", "")
+	rootCommand.AddFlag("count", "c", false, "1")
+
+	// parse concurrently
+	var wg sync.WaitGroup
+
+	for index := 0; index < 20; index++ {
+		wg.Add(1)
+
+		go func(index int) {
+			defer wg.Done()
+
+			name, count := fmt.Sprintf("name-%d", index), strconv.Itoa(index)
+
+			result, err := registry.ParseArgs([]string{name, "--count", count})
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if result.Command != rootCommand || result.Args["name"].Value != name || result.Flags["count"].Value != count {
+				t.Errorf("unexpected result for %s: %#v", name, result)
+			}
+		}(index)
+	}
+
+	wg.Wait()
+
+	// registered flags and arguments are not modified
+	if flag := rootCommand.Flags["count"]; flag.IsSet || len(flag.Value) != 0 || flag.Count != 0 {
+		t.Errorf("expected registered flag without value, got %#v", flag)
+	}
+
+	if arg := rootCommand.Args["name"]; arg.IsSet || len(arg.Value) != 0 {
+		t.Errorf("expected registered argument without value, got %#v", arg)
+	}
+}
+
+// test persistent flags inherited by sub-commands
+func TestPersistentFlags(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	rootCommand, _ := registry.Register("")
+	verboseFlag, _ := rootCommand.AddFlag("verbose", "v", true, "")
+	verboseFlag.IsPersistent = true
+	configFlag, _ := rootCommand.AddFlag("config", "c", false, "config.toml")
+	configFlag.IsPersistent = true
+
+	remoteCommand, _ := registry.Register("remote")
+	timeoutFlag, _ := remoteCommand.AddFlag("timeout", "t", false, "10s")
+	timeoutFlag.IsPersistent = true
+	remoteCommand.AddFlag("dry-run", "", true, "")
+
+	addCommand, _ := remoteCommand.Register("add")
+	addCommand.AddArg("name", "")
+	addCommand.AddFlag("config", "", false, "remote.toml")
+
+	// persistent flags before and after the sub-command names
+	for _, values := range [][]string{
+		{"-v", "remote", "add", "origin", "--timeout", "5s"},
+		{"--verbose", "remote", "-t", "5s", "add", "origin"},
+		{"remote", "--timeout=5s", "add", "-v", "origin"},
+	} {
+		result, err := registry.ParseArgs(values)
+		if err != nil {
+			t.Fatalf("unexpected error for %#v: %v", values, err)
+		}
+
+		if result.Command != addCommand || result.Args["name"].Value != "origin" {
+			t.Errorf("expected `remote add origin` for %#v, got %#v", values, result.Command.Path)
+		}
+
+		if result.Flags["verbose"].Value != "true" || result.Flags["timeout"].Value != "5s" {
+			t.Errorf("unexpected values for %#v: verbose=%#v timeout=%#v", values, result.Flags["verbose"].Value, result.Flags["timeout"].Value)
+		}
+
+		// a flag of the command overrides the inherited flag
+		if flag := result.Flags["config"]; flag.DefaultValue != "remote.toml" {
+			t.Errorf("expected the config flag of the command, got %#v", flag)
+		}
+	}
+
+	// non-persistent flags are not inherited
+	if _, err := registry.ParseArgs([]string{"remote", "add", "origin", "--dry-run"}); !reflect.DeepEqual(err, ErrorUnknownFlag{"--dry-run", nil}) {
+		t.Errorf("expected unknown flag error, got %#v", err)
+	}
+
+	// values of the inherited flags are written into the registered flags by the `Parse` method
+	if _, err := registry.Parse([]string{"-c", "app.toml", "remote", "origin"}); !reflect.DeepEqual(err, ErrorUnknownCommand{"origin", nil}) {
+		t.Errorf("expected unknown command error, got %#v", err)
+	}
+
+	if _, err := registry.Parse([]string{"-c", "app.toml", "remote"}); err != nil || configFlag.Value != "app.toml" {
+		t.Errorf("expected config value in the registered flag, got %#v (%v)", configFlag.Value, err)
+	}
+}
+
+// test flags of the root command before the command name
+func TestRootFlagsBeforeCommand(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	rootCommand, _ := registry.Register("")
+	rootCommand.AddArg("output", "")
+	rootVerboseFlag, _ := rootCommand.AddFlag("verbose", "v", true, "")
+	rootCommand.AddFlag("config", "c", false, "config.toml")
+
+	infoCommand, _ := registry.Register("info")
+	infoCommand.AddArg("name", "")
+	infoCommand.AddFlag("verbose", "v", true, "")
+
+	// root flags are dispatched to the sub-command
+	result, err := registry.ParseArgs([]string{"-v", "--config=app.toml", "info", "alice"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Command != infoCommand || result.Args["name"].Value != "alice" {
+		t.Errorf("expected `info alice`, got %#v", result.Command.Path)
+	}
+
+	if result.RootFlags["verbose"].Value != "true" || result.RootFlags["config"].Value != "app.toml" || result.Flags["verbose"].IsSet {
+		t.Errorf("unexpected values: root=%#v command=%#v", result.RootFlags["verbose"], result.Flags["verbose"])
+	}
+
+	// a value which is not a command name is an argument of the root command
+	for _, values := range [][]string{{"-v", "alice"}, {"-v", "--", "info"}, {"-c", "info"}} {
+		result, err := registry.ParseArgs(values)
+		if err != nil {
+			t.Fatalf("unexpected error for %#v: %v", values, err)
+		}
+
+		if result.Command != rootCommand || len(result.Flags) != len(result.RootFlags) {
+			t.Errorf("expected root command for %#v, got %#v", values, result.Command.Path)
+		}
+	}
+
+	// values of the root flags are written into the registered flags by the `Parse` method
+	if command, err := registry.Parse([]string{"-v", "info"}); err != nil || command != infoCommand || rootVerboseFlag.Value != "true" {
+		t.Errorf("expected verbose root flag, got %#v (%v)", rootVerboseFlag.Value, err)
+	}
+}
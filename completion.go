@@ -0,0 +1,751 @@
+// MIT License
+
+// Copyright (c) 2020 Uday Hiwarale
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package clapper
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// completionCommand holds the information about a command required to generate completion scripts.
+type completionCommand struct {
+
+	// full path of the command ("" for the root command)
+	path string
+
+	// sub-command names and their descriptions
+	commands [][2]string
+
+	// aliases of the sub-commands (keyed by the sub-command name)
+	aliases map[string][]string
+
+	// flags of the command
+	flags []*completionFlag
+
+	// if the command accepts arguments
+	hasArgs bool
+
+	// if values of the arguments are completed by a callback
+	hasDynamicArgs bool
+}
+
+// completionFlag holds the information about a flag required to generate completion scripts.
+type completionFlag struct {
+
+	// long name of the flag with `--` prefix (`--no-` prefix for an inverted flag)
+	long string
+
+	// short name of the flag with `-` prefix
+	short string
+
+	// description of the flag
+	description string
+
+	// if the flag takes a value
+	takesValue bool
+
+	// if the value of the flag is completed by a callback
+	isDynamic bool
+}
+
+// names of the flag (with `-` or `--` prefix)
+func (flag *completionFlag) names() []string {
+
+	names := make([]string, 0, 2)
+
+	if len(flag.long) != 0 {
+		names = append(names, flag.long)
+	}
+
+	if len(flag.short) != 0 {
+		names = append(names, flag.short)
+	}
+
+	return names
+}
+
+// get completion information of the root command and all sub-commands (sorted by path)
+func (registry *Registry) completionCommands() []*completionCommand {
+
+	completionCommands := make([]*completionCommand, 0)
+
+	var walk func(path string, commandConfig *CommandConfig, subCommands map[string]*CommandConfig)
+	walk = func(path string, commandConfig *CommandConfig, subCommands map[string]*CommandConfig) {
+
+		completion := &completionCommand{
+			path:     path,
+			commands: make([][2]string, 0),
+			aliases:  make(map[string][]string),
+			flags:    make([]*completionFlag, 0),
+		}
+
+		completionCommands = append(completionCommands, completion)
+
+		// sub-commands
+		names := make([]string, 0, len(subCommands))
+		for name := range subCommands {
+			if len(name) != 0 {
+				names = append(names, name)
+			}
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			completion.commands = append(completion.commands, [2]string{name, summary(subCommands[name].Description)})
+
+			if len(subCommands[name].Aliases) > 0 {
+				completion.aliases[name] = subCommands[name].Aliases
+			}
+		}
+
+		// flags (including the inherited persistent flags)
+		if commandConfig == nil {
+			commandConfig = newCommandConfig("", nil)
+		} else {
+			commandConfig = registry.resolve(commandConfig)
+		}
+
+		completion.flags = completionFlags(commandConfig)
+
+		completion.hasArgs = len(commandConfig.Args) > 0
+
+		for _, arg := range commandConfig.Args {
+			if arg.Complete != nil {
+				completion.hasDynamicArgs = true
+			}
+		}
+
+		// walk sub-commands
+		for _, name := range names {
+			walk(strings.TrimSpace(path+" "+name), subCommands[name], subCommands[name].SubCommands)
+		}
+	}
+
+	walk("", registry.Commands[""], registry.Commands)
+
+	return completionCommands
+}
+
+// get completion information of the flags of a command (sorted by name) including the built-in help flag
+func completionFlags(commandConfig *CommandConfig) []*completionFlag {
+
+	flags := make([]*completionFlag, 0)
+
+	for _, flagName := range commandConfig.flagNames() {
+		flag := commandConfig.Flags[flagName]
+
+		completionFlag := &completionFlag{
+			long:        "--" + flagName,
+			description: summary(flag.Description),
+			takesValue:  !flag.IsBoolean,
+			isDynamic:   !flag.IsBoolean && flag.Complete != nil,
+		}
+
+		if flag.IsInverted {
+			completionFlag.long = "--no-" + flagName
+		}
+
+		if len(flag.ShortName) != 0 {
+			completionFlag.short = "-" + flag.ShortName
+		}
+
+		flags = append(flags, completionFlag)
+	}
+
+	// built-in help flag
+	helpFlag := &completionFlag{description: "show help"}
+
+	if isHelpFlag("--help", commandConfig) {
+		helpFlag.long = "--help"
+	}
+
+	if isHelpFlag("-h", commandConfig) {
+		helpFlag.short = "-h"
+	}
+
+	if len(helpFlag.names()) > 0 {
+		flags = append(flags, helpFlag)
+	}
+
+	return flags
+}
+
+// transitions between command paths in the `<parent path>:<sub-command name>` format
+func completionTransitions(completionCommands []*completionCommand) []string {
+
+	transitions := make([]string, 0)
+
+	for _, completion := range completionCommands {
+		for _, command := range completion.commands {
+			transitions = append(transitions, fmt.Sprintf(`"%s:%s"`, completion.path, command[0]))
+		}
+	}
+
+	return transitions
+}
+
+// completionAlias holds the transitions of the aliases of a sub-command to the path of the sub-command.
+type completionAlias struct {
+
+	// transitions in the `<parent path>:<alias>` format
+	transitions []string
+
+	// full path of the sub-command
+	path string
+}
+
+// transitions of the sub-command aliases (sorted by the path of the sub-command)
+func completionAliases(completionCommands []*completionCommand) []*completionAlias {
+
+	aliases := make([]*completionAlias, 0)
+
+	for _, completion := range completionCommands {
+		for _, command := range completion.commands {
+			if len(completion.aliases[command[0]]) == 0 {
+				continue
+			}
+
+			alias := &completionAlias{
+				transitions: make([]string, 0),
+				path:        strings.TrimSpace(completion.path + " " + command[0]),
+			}
+
+			for _, name := range completion.aliases[command[0]] {
+				alias.transitions = append(alias.transitions, fmt.Sprintf(`"%s:%s"`, completion.path, name))
+			}
+
+			aliases = append(aliases, alias)
+		}
+	}
+
+	return aliases
+}
+
+// names of the flags which take a value in the `<command path>:<flag name>` format
+// the `isDynamic` argument selects the flags with (or without) a completion callback
+func completionValueFlags(completionCommands []*completionCommand, isDynamic bool) []string {
+
+	values := make([]string, 0)
+
+	for _, completion := range completionCommands {
+		for _, flag := range completion.flags {
+			if flag.takesValue && flag.isDynamic == isDynamic {
+				for _, name := range flag.names() {
+					values = append(values, fmt.Sprintf(`"%s:%s"`, completion.path, name))
+				}
+			}
+		}
+	}
+
+	return values
+}
+
+// name of the program usable as a shell function name
+func completionFunctionName(program string) string {
+	return regexp.MustCompile(`[^a-zA-Z0-9_]`).ReplaceAllString(program, "_")
+}
+
+// quote a value using single quotes for a shell script
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+/*---------------------*/
+
+// BashCompletion method returns a bash completion script for the commands registered in the registry.
+// The `program` argument is the name of the program to complete.
+// The script can be loaded using `source <(program completion-command)` or installed in the bash-completion directory.
+func (registry *Registry) BashCompletion(program string) string {
+
+	completionCommands := registry.completionCommands()
+	function := "_" + completionFunctionName(program) + "_completion"
+
+	builder := &strings.Builder{}
+
+	fmt.Fprintf(builder, "# bash completion for %s\n\n", program)
+	fmt.Fprintf(builder, "%s() {\n", function)
+	builder.WriteString("    local cur prev word path i\n")
+	builder.WriteString("    cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	builder.WriteString("    prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	builder.WriteString("    path=\"\"\n\n")
+
+	// find the command path
+	builder.WriteString("    for ((i = 1; i < COMP_CWORD; i++)); do\n")
+	builder.WriteString("        word=\"${COMP_WORDS[i]}\"\n")
+	builder.WriteString("        case \"${path}:${word}\" in\n")
+	if transitions := completionTransitions(completionCommands); len(transitions) > 0 {
+		fmt.Fprintf(builder, "            %s)\n", strings.Join(transitions, "|"))
+		builder.WriteString("                path=\"${path:+${path} }${word}\"\n")
+		builder.WriteString("                ;;\n")
+	}
+	for _, alias := range completionAliases(completionCommands) {
+		fmt.Fprintf(builder, "            %s)\n", strings.Join(alias.transitions, "|"))
+		fmt.Fprintf(builder, "                path=\"%s\"\n", alias.path)
+		builder.WriteString("                ;;\n")
+	}
+	builder.WriteString("        esac\n")
+	builder.WriteString("    done\n\n")
+
+	// candidates provided by completion callbacks
+	dynamic := fmt.Sprintf("$(%s __complete \"${COMP_WORDS[@]:1:COMP_CWORD}\")", program)
+
+	// complete values of flags using completion callbacks or the default completion
+	dynamicValues := completionValueFlags(completionCommands, true)
+	values := completionValueFlags(completionCommands, false)
+
+	if len(dynamicValues) > 0 || len(values) > 0 {
+		builder.WriteString("    case \"${path}:${prev}\" in\n")
+		if len(dynamicValues) > 0 {
+			fmt.Fprintf(builder, "        %s)\n", strings.Join(dynamicValues, "|"))
+			fmt.Fprintf(builder, "            COMPREPLY=($(compgen -W \"%s\" -- \"${cur}\"))\n", dynamic)
+			builder.WriteString("            return\n")
+			builder.WriteString("            ;;\n")
+		}
+		if len(values) > 0 {
+			fmt.Fprintf(builder, "        %s)\n", strings.Join(values, "|"))
+			builder.WriteString("            COMPREPLY=()\n")
+			builder.WriteString("            return\n")
+			builder.WriteString("            ;;\n")
+		}
+		builder.WriteString("    esac\n\n")
+	}
+
+	// complete sub-commands and flags
+	builder.WriteString("    local words=\"\"\n")
+	builder.WriteString("    case \"${path}\" in\n")
+	for _, completion := range completionCommands {
+		words := make([]string, 0)
+		for _, command := range completion.commands {
+			words = append(words, command[0])
+		}
+
+		for _, flag := range completion.flags {
+			words = append(words, flag.names()...)
+		}
+
+		fmt.Fprintf(builder, "        \"%s\")\n", completion.path)
+		if completion.hasDynamicArgs {
+			fmt.Fprintf(builder, "            words=\"%s\"\n", dynamic)
+		} else {
+			fmt.Fprintf(builder, "            words=\"%s\"\n", strings.Join(words, " "))
+		}
+		builder.WriteString("            ;;\n")
+	}
+	builder.WriteString("    esac\n\n")
+
+	builder.WriteString("    COMPREPLY=($(compgen -W \"${words}\" -- \"${cur}\"))\n")
+	builder.WriteString("}\n\n")
+
+	// arguments are completed using the default completion
+	fmt.Fprintf(builder, "complete -o default -F %s %s\n", function, program)
+
+	return builder.String()
+}
+
+// ZshCompletion method returns a zsh completion script for the commands registered in the registry.
+// The `program` argument is the name of the program to complete.
+// The script can be loaded using `source <(program completion-command)` or installed as `_program` file in the `$fpath` directory.
+func (registry *Registry) ZshCompletion(program string) string {
+
+	completionCommands := registry.completionCommands()
+	function := "_" + completionFunctionName(program)
+
+	builder := &strings.Builder{}
+
+	fmt.Fprintf(builder, "#compdef %s\n\n", program)
+	fmt.Fprintf(builder, "%s() {\n", function)
+	builder.WriteString("    local cmdpath=\"\" word i\n")
+	builder.WriteString("    local -a candidates\n\n")
+
+	// find the command path
+	builder.WriteString("    for ((i = 2; i < CURRENT; i++)); do\n")
+	builder.WriteString("        word=\"${words[i]}\"\n")
+	builder.WriteString("        case \"${cmdpath}:${word}\" in\n")
+	if transitions := completionTransitions(completionCommands); len(transitions) > 0 {
+		fmt.Fprintf(builder, "            %s)\n", strings.Join(transitions, "|"))
+		builder.WriteString("                cmdpath=\"${cmdpath:+${cmdpath} }${word}\"\n")
+		builder.WriteString("                ;;\n")
+	}
+	for _, alias := range completionAliases(completionCommands) {
+		fmt.Fprintf(builder, "            %s)\n", strings.Join(alias.transitions, "|"))
+		fmt.Fprintf(builder, "                cmdpath=\"%s\"\n", alias.path)
+		builder.WriteString("                ;;\n")
+	}
+	builder.WriteString("        esac\n")
+	builder.WriteString("    done\n\n")
+
+	// candidates provided by completion callbacks
+	dynamic := fmt.Sprintf("candidates=(${(f)\"$(%s __complete \"${(@)words[2,CURRENT]}\")\"})", program)
+
+	// complete values of flags using completion callbacks or file completion
+	dynamicValues := completionValueFlags(completionCommands, true)
+	values := completionValueFlags(completionCommands, false)
+
+	if len(dynamicValues) > 0 || len(values) > 0 {
+		builder.WriteString("    case \"${cmdpath}:${words[CURRENT-1]}\" in\n")
+		if len(dynamicValues) > 0 {
+			fmt.Fprintf(builder, "        %s)\n", strings.Join(dynamicValues, "|"))
+			fmt.Fprintf(builder, "            %s\n", dynamic)
+			builder.WriteString("            compadd -a candidates\n")
+			builder.WriteString("            return\n")
+			builder.WriteString("            ;;\n")
+		}
+		if len(values) > 0 {
+			fmt.Fprintf(builder, "        %s)\n", strings.Join(values, "|"))
+			builder.WriteString("            _files\n")
+			builder.WriteString("            return\n")
+			builder.WriteString("            ;;\n")
+		}
+		builder.WriteString("    esac\n\n")
+	}
+
+	// complete sub-commands and flags
+	builder.WriteString("    case \"${cmdpath}\" in\n")
+	for _, completion := range completionCommands {
+		fmt.Fprintf(builder, "        \"%s\")\n", completion.path)
+
+		// sub-commands, flags and arguments are completed by completion callbacks
+		if completion.hasDynamicArgs {
+			fmt.Fprintf(builder, "            %s\n", dynamic)
+			builder.WriteString("            compadd -a candidates\n")
+			builder.WriteString("            return\n")
+			builder.WriteString("            ;;\n")
+			continue
+		}
+
+		builder.WriteString("            candidates=(\n")
+		candidates := append(make([][2]string, 0), completion.commands...)
+		for _, flag := range completion.flags {
+			for _, name := range flag.names() {
+				candidates = append(candidates, [2]string{name, flag.description})
+			}
+		}
+
+		for _, candidate := range candidates {
+			value := strings.ReplaceAll(candidate[0], ":", `\:`)
+			if len(candidate[1]) != 0 {
+				value += ":" + candidate[1]
+			}
+
+			fmt.Fprintf(builder, "                %s\n", shellQuote(value))
+		}
+		builder.WriteString("            )\n")
+
+		if completion.hasArgs {
+			builder.WriteString("            _files\n")
+		}
+
+		builder.WriteString("            ;;\n")
+	}
+	builder.WriteString("    esac\n\n")
+
+	builder.WriteString("    _describe -t candidates 'candidates' candidates\n")
+	builder.WriteString("}\n\n")
+
+	fmt.Fprintf(builder, "if [[ \"${funcstack[1]}\" = \"%s\" ]]; then\n", function)
+	fmt.Fprintf(builder, "    %s \"$@\"\n", function)
+	builder.WriteString("else\n")
+	fmt.Fprintf(builder, "    compdef %s %s\n", function, program)
+	builder.WriteString("fi\n")
+
+	return builder.String()
+}
+
+// FishCompletion method returns a fish completion script for the commands registered in the registry.
+// The `program` argument is the name of the program to complete.
+// The script can be loaded using `program completion-command | source` or installed in the fish completions directory.
+func (registry *Registry) FishCompletion(program string) string {
+
+	completionCommands := registry.completionCommands()
+	function := "__" + completionFunctionName(program)
+
+	builder := &strings.Builder{}
+
+	fmt.Fprintf(builder, "# fish completion for %s\n\n", program)
+
+	// find the command path
+	fmt.Fprintf(builder, "function %s_path\n", function)
+	builder.WriteString("    set -l path \"\"\n")
+	builder.WriteString("    for word in (commandline -opc)[2..-1]\n")
+	builder.WriteString("        switch \"$path:$word\"\n")
+	if transitions := completionTransitions(completionCommands); len(transitions) > 0 {
+		fmt.Fprintf(builder, "            case %s\n", strings.Join(transitions, " "))
+		builder.WriteString("                set path (string trim -- \"$path $word\")\n")
+	}
+	for _, alias := range completionAliases(completionCommands) {
+		fmt.Fprintf(builder, "            case %s\n", strings.Join(alias.transitions, " "))
+		fmt.Fprintf(builder, "                set path \"%s\"\n", alias.path)
+	}
+	builder.WriteString("        end\n")
+	builder.WriteString("    end\n")
+	builder.WriteString("    echo $path\n")
+	builder.WriteString("end\n\n")
+
+	// check the command path
+	fmt.Fprintf(builder, "function %s_using_path\n", function)
+	fmt.Fprintf(builder, "    set -l path (%s_path)\n", function)
+	builder.WriteString("    test \"$argv[1]\" = \"$path\"\n")
+	builder.WriteString("end\n")
+
+	// candidates provided by completion callbacks
+	dynamic := shellQuote(fmt.Sprintf("(%s __complete (commandline -opc)[2..-1] (commandline -ct))", program))
+
+	for _, completion := range completionCommands {
+		condition := fmt.Sprintf("-n %s", shellQuote(fmt.Sprintf(`%s_using_path "%s"`, function, completion.path)))
+
+		fmt.Fprintf(builder, "\n# %s\n", strings.TrimSpace(program+" "+completion.path))
+
+		// arguments are completed using completion callbacks or file completion
+		if completion.hasDynamicArgs {
+			fmt.Fprintf(builder, "complete -c %s %s -f -a %s\n", program, condition, dynamic)
+		} else if !completion.hasArgs {
+			fmt.Fprintf(builder, "complete -c %s %s -f\n", program, condition)
+		}
+
+		for _, command := range completion.commands {
+			line := fmt.Sprintf("complete -c %s %s -f -a %s", program, condition, shellQuote(command[0]))
+			if len(command[1]) != 0 {
+				line += " -d " + shellQuote(command[1])
+			}
+
+			builder.WriteString(line + "\n")
+		}
+
+		for _, flag := range completion.flags {
+			line := fmt.Sprintf("complete -c %s %s", program, condition)
+
+			if len(flag.long) != 0 {
+				line += " -l " + strings.TrimPrefix(flag.long, "--")
+			}
+
+			if len(flag.short) != 0 {
+				line += " -s " + strings.TrimPrefix(flag.short, "-")
+			}
+
+			if flag.isDynamic {
+				line += " -r -f -a " + dynamic
+			} else if flag.takesValue {
+				line += " -r"
+			}
+
+			if len(flag.description) != 0 {
+				line += " -d " + shellQuote(flag.description)
+			}
+
+			builder.WriteString(line + "\n")
+		}
+	}
+
+	return builder.String()
+}
+
+/*---------------------*/
+
+// ErrorCompletionRequested represents an error when command-line arguments start with the hidden `__complete` command.
+// The `Candidates` field contains the completion candidates, which the program should print to the standard output (one per line)
+// for the completion scripts.
+type ErrorCompletionRequested struct {
+	Candidates []string
+}
+
+func (e ErrorCompletionRequested) Error() string {
+	return fmt.Sprintf("completion requested with %d candidates", len(e.Candidates))
+}
+
+// flag which takes the value following the `value` flag (`nil` if the `value` does not expect a value)
+func completionValueFlag(value string, commandConfig *CommandConfig) *Flag {
+
+	flagValues := []string{value}
+
+	// a value attached to a flag (like `--flag=value` or `-fvalue`) is complete
+	if strings.Contains(value, "=") && !isShortFlagCluster(value) {
+		return nil
+	}
+
+	if isShortFlagCluster(value) {
+		var hasAttachedValue bool
+		var err error
+		if flagValues, _, hasAttachedValue, err = splitShortFlagCluster(value, commandConfig); err != nil || hasAttachedValue {
+			return nil
+		}
+	}
+
+	// only the last flag can take a value
+	if flag, err := commandConfig.lookupFlag(flagValues[len(flagValues)-1]); err == nil && !flag.IsBoolean && !flag.IsValueOptional {
+		return flag
+	}
+
+	return nil
+}
+
+// complete method returns completion candidates of the last command-line argument value in the `values`.
+func (registry *Registry) complete(values []string) []string {
+
+	candidates := make([]string, 0)
+
+	// the word being completed and the preceding words
+	word := ""
+	if len(values) > 0 {
+		word, values = values[len(values)-1], values[:len(values)-1]
+	}
+
+	// find the command (the same way as the `ParseArgs` method)
+	commandConfig := registry.Commands[""]
+	subCommands := registry.Commands
+
+	// flags of the root command preceding the command name
+	if commandConfig != nil && len(values) > 0 && isFlag(values[0]) {
+		flagValues, rest := registry.splitLeadingFlags(values, commandConfig, false)
+
+		if len(flagValues) > 0 && len(rest) > 0 && registry.isCommandName(registry.Commands, rest[0]) {
+			values = rest
+		}
+	}
+
+	// persistent flags preceding the sub-command names are values of the matched command
+	hoisted := make([]string, 0)
+
+	for len(values) > 0 && len(subCommands) > 0 {
+		flagValues, rest := values[:0], values
+		if commandConfig != nil && commandConfig != registry.Commands[""] {
+			flagValues, rest = registry.splitLeadingFlags(values, commandConfig, true)
+		}
+
+		if len(rest) == 0 || isFlag(rest[0]) {
+			break
+		}
+
+		subCommandConfig, err := registry.lookupCommand(subCommands, rest[0])
+		if err != nil {
+			break
+		}
+
+		commandConfig, subCommands = subCommandConfig, subCommandConfig.SubCommands
+		hoisted = append(hoisted, flagValues...)
+		values = rest[1:]
+	}
+
+	values = append(hoisted, values...)
+
+	// list registered commands if the root command is not registered
+	if commandConfig == nil {
+		if len(values) == 0 {
+			for commandName := range registry.Commands {
+				if strings.HasPrefix(commandName, word) {
+					candidates = append(candidates, commandName)
+				}
+			}
+		}
+
+		sort.Strings(candidates)
+		return candidates
+	}
+
+	// flags of the command including the inherited persistent flags
+	commandConfig = registry.resolve(commandConfig)
+
+	// count argument values and check if the word is a value of a flag
+	argCount, isTerminated := 0, false
+
+	for index := 0; index < len(values); index++ {
+		value := values[index]
+
+		if isTerminated || !isFlag(value) {
+			argCount++
+			continue
+		}
+
+		if isEndOfFlags(value) {
+			isTerminated = true
+			continue
+		}
+
+		if flag := completionValueFlag(value, commandConfig); flag != nil {
+			if index == len(values)-1 {
+				if flag.Complete != nil {
+					return flag.Complete(word)
+				}
+
+				return candidates
+			}
+
+			index++ // skip the value of the flag
+		}
+	}
+
+	// complete value of a flag attached with `=` (like `--flag=value`)
+	if !isTerminated && strings.HasPrefix(word, "--") && strings.Contains(word, "=") {
+		parts := strings.SplitN(word, "=", 2)
+
+		if flag, err := commandConfig.lookupFlag(parts[0]); err == nil && !flag.IsBoolean && flag.Complete != nil {
+			for _, candidate := range flag.Complete(parts[1]) {
+				candidates = append(candidates, parts[0]+"="+candidate)
+			}
+		}
+
+		return candidates
+	}
+
+	// complete flag names
+	if !isTerminated && strings.HasPrefix(word, "-") {
+		for _, flag := range completionFlags(commandConfig) {
+			for _, name := range flag.names() {
+				if strings.HasPrefix(name, word) {
+					candidates = append(candidates, name)
+				}
+			}
+		}
+
+		return candidates
+	}
+
+	// complete sub-command names
+	if argCount == 0 && !isTerminated {
+		commandNames := make([]string, 0)
+		for commandName := range subCommands {
+			if len(commandName) != 0 && strings.HasPrefix(commandName, word) {
+				commandNames = append(commandNames, commandName)
+			}
+		}
+
+		sort.Strings(commandNames)
+		candidates = append(candidates, commandNames...)
+	}
+
+	// complete argument values
+	if len(commandConfig.ArgNames) > 0 {
+		index := argCount
+		if index >= len(commandConfig.ArgNames) {
+			index = len(commandConfig.ArgNames) - 1
+		}
+
+		if arg := commandConfig.Args[commandConfig.ArgNames[index]]; (argCount == index || arg.IsVariadic) && arg.Complete != nil {
+			candidates = append(candidates, arg.Complete(word)...)
+		}
+	}
+
+	return candidates
+}
@@ -0,0 +1,152 @@
+package clapper
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// completion callback of remote names
+func completeRemotes(word string) []string {
+	candidates := make([]string, 0)
+	for _, remote := range []string{"origin", "upstream"} {
+		if strings.HasPrefix(remote, word) {
+			candidates = append(candidates, remote)
+		}
+	}
+
+	return candidates
+}
+
+// completion callback of branch names
+func completeBranches(word string) []string {
+	return []string{word + "main", word + "develop"}
+}
+
+// test generated completion scripts against snapshots
+func TestCompletionScripts(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	rootCommand, _ := registry.Register("")
+	rootCommand.AddFlag("verbose", "v", true, "")
+	configFlag, _ := rootCommand.AddFlag("config", "c", false, "")
+	configFlag.IsPersistent = true
+
+	remoteCommand, _ := registry.Register("remote")
+	remoteCommand.Description = "Manage remotes"
+
+	addCommand, _ := remoteCommand.Register("add")
+	addCommand.Description = "Add a remote"
+	addCommand.AddArg("name", "")
+	branchFlag, _ := addCommand.AddFlag("branch", "b", false, "master")
+	branchFlag.Description = "Branch to track"
+	fetchFlag, _ := addCommand.AddFlag("no-fetch", "", true, "")
+	fetchFlag.Description = "Don't fetch the remote"
+
+	removeCommand, _ := remoteCommand.Register("remove", "rm")
+	nameArg, _ := removeCommand.AddArg("name", "")
+	nameArg.Complete = completeRemotes
+
+	pushCommand, _ := registry.Register("push")
+	pushCommand.AddArg("remote", "")
+	pushCommand.AddArg("branches...", "")
+	pushCommand.Args["branches"].Complete = completeBranches
+	pushCommand.AddFlag("force", "f", true, "")
+	upstreamFlag, _ := pushCommand.AddFlag("set-upstream", "u", false, "")
+	upstreamFlag.Complete = completeRemotes
+
+	registry.Register("status", "st")
+
+	// snapshot files
+	scripts := map[string]string{
+		"testdata/completion.bash": registry.BashCompletion("tool"),
+		"testdata/completion.zsh":  registry.ZshCompletion("tool"),
+		"testdata/completion.fish": registry.FishCompletion("tool"),
+	}
+
+	for path, script := range scripts {
+		snapshot, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("can not read snapshot: %v", err)
+		}
+
+		if script != string(snapshot) {
+			t.Errorf("completion script does not match %s snapshot:\n%s", path, script)
+		}
+	}
+}
+
+// test completion candidates of the hidden `__complete` command
+func TestCompletionCandidates(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	rootCommand, _ := registry.Register("")
+	rootCommand.AddFlag("verbose", "v", true, "")
+	configFlag, _ := rootCommand.AddFlag("config", "c", false, "")
+	configFlag.IsPersistent = true
+
+	remoteCommand, _ := registry.Register("remote")
+	remoteCommand.Description = "Manage remotes"
+
+	addCommand, _ := remoteCommand.Register("add")
+	addCommand.Description = "Add a remote"
+	addCommand.AddArg("name", "")
+	branchFlag, _ := addCommand.AddFlag("branch", "b", false, "master")
+	branchFlag.Description = "Branch to track"
+	fetchFlag, _ := addCommand.AddFlag("no-fetch", "", true, "")
+	fetchFlag.Description = "Don't fetch the remote"
+
+	removeCommand, _ := remoteCommand.Register("remove", "rm")
+	nameArg, _ := removeCommand.AddArg("name", "")
+	nameArg.Complete = completeRemotes
+
+	pushCommand, _ := registry.Register("push")
+	pushCommand.AddArg("remote", "")
+	pushCommand.AddArg("branches...", "")
+	pushCommand.Args["branches"].Complete = completeBranches
+	pushCommand.AddFlag("force", "f", true, "")
+	upstreamFlag, _ := pushCommand.AddFlag("set-upstream", "u", false, "")
+	upstreamFlag.Complete = completeRemotes
+
+	registry.Register("status", "st")
+
+	// candidates of command-line argument values
+	candidates := map[string]string{
+		"":                           "push remote status",
+		"re":                         "remote",
+		"-":                          "--config -c --verbose -v --help -h",
+		"remote ":                    "add remove",
+		"remote add --b":             "--branch",
+		"remote add -b ":             "",
+		"remote remove ":             "origin upstream",
+		"remote remove up":           "upstream",
+		"remote rm ":                 "origin upstream",
+		"st -":                       "--config -c --help -h",
+		"remote remove origin ":      "",
+		"push origin ":               "main develop",
+		"push origin main x":         "xmain xdevelop",
+		"push -fu ":                  "origin upstream",
+		"push --set-upstream=o":      "--set-upstream=origin",
+		"push -u origin -- origin -": "-main -develop",
+		"--verbose remote ":          "add remove",
+		"-c ./cfg remote add --":     "--branch --config --no-fetch --help",
+		"remote -c ./cfg add -":      "--branch -b --config -c --no-fetch --help -h",
+		"remote add -c ":             "",
+	}
+
+	for values, expected := range candidates {
+		if result := strings.Join(registry.complete(strings.Split(values, " ")), " "); result != expected {
+			t.Errorf("expected candidates %#v for %#v, got %#v", expected, values, result)
+		}
+	}
+
+	// candidates are returned by the hidden `__complete` command
+	if _, err := registry.ParseArgs([]string{"__complete", "remote", ""}); !reflect.DeepEqual(err, ErrorCompletionRequested{[]string{"add", "remove"}}) {
+		t.Errorf("expected completion error, got %#v", err)
+	}
+}
@@ -0,0 +1,763 @@
+// MIT License
+
+// Copyright (c) 2020 Uday Hiwarale
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package clapper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ConfigFormat represents the format of a config file.
+type ConfigFormat string
+
+// supported formats of config files
+const (
+	ConfigJSON ConfigFormat = "json"
+	ConfigTOML ConfigFormat = "toml"
+	ConfigYAML ConfigFormat = "yaml"
+	ConfigINI  ConfigFormat = "ini"
+)
+
+// formats of config files by file extension
+var configFormats = map[string]ConfigFormat{
+	".json": ConfigJSON,
+	".toml": ConfigTOML,
+	".yaml": ConfigYAML,
+	".yml":  ConfigYAML,
+	".ini":  ConfigINI,
+}
+
+// a config tree holds values of flags (`string` or `[]string`) and config trees of sub-commands (`map[string]interface{}`)
+type configTree map[string]interface{}
+
+// get nested config tree for the `path`, created if not exists
+func (tree configTree) subTree(path []string) (configTree, error) {
+	for _, name := range path {
+		value, ok := tree[name]
+		if !ok {
+			value = configTree{}
+			tree[name] = value
+		}
+
+		subTree, ok := value.(configTree)
+		if !ok {
+			return nil, fmt.Errorf("key %s is not a table", name)
+		}
+
+		tree = subTree
+	}
+
+	return tree, nil
+}
+
+// set value of a key, values of a repeated key are collected
+func (tree configTree) set(key string, value interface{}) {
+	existing, ok := tree[key]
+	if !ok {
+		tree[key] = value
+		return
+	}
+
+	values := make([]string, 0)
+	for _, v := range []interface{}{existing, value} {
+		switch v := v.(type) {
+		case string:
+			values = append(values, v)
+		case []string:
+			values = append(values, v...)
+		}
+	}
+
+	tree[key] = values
+}
+
+// flatten the config tree into values of flags keyed by the command path and the flag name
+func (tree configTree) flatten(path string, config map[string]map[string][]string) {
+	for key, value := range tree {
+		switch value := value.(type) {
+		case string:
+			if _, ok := config[path]; !ok {
+				config[path] = make(map[string][]string)
+			}
+
+			config[path][key] = []string{value}
+		case []string:
+			if _, ok := config[path]; !ok {
+				config[path] = make(map[string][]string)
+			}
+
+			config[path][key] = value
+		case configTree:
+			value.flatten(strings.TrimSpace(path+" "+key), config)
+		}
+	}
+}
+
+/*---------------------*/
+
+// ErrorInvalidConfig represents an error when a config file can not be read or parsed.
+// The `Name` field contains the path (or the format) of the config and the `Line` field contains the line number of the error (if known).
+type ErrorInvalidConfig struct {
+	Name   string
+	Line   int
+	Reason string
+}
+
+func (e ErrorInvalidConfig) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("invalid config %s at line %d: %s", strconv.Quote(e.Name), e.Line, e.Reason)
+	}
+
+	return fmt.Sprintf("invalid config %s: %s", strconv.Quote(e.Name), e.Reason)
+}
+
+/*---------------------*/
+
+// LoadConfigFile method loads default values of the flags from a config file.
+// The format of the file is detected from its extension (".json", ".toml", ".yaml", ".yml" or ".ini").
+// If the file can not be read or parsed, it returns `ErrorInvalidConfig` error.
+func (registry *Registry) LoadConfigFile(path string) error {
+
+	format, ok := configFormats[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return ErrorInvalidConfig{path, 0, "unsupported file extension"}
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ErrorInvalidConfig{path, 0, err.Error()}
+	}
+
+	return registry.loadConfig(path, data, format)
+}
+
+// LoadConfig method loads default values of the flags from a config in the `format` format.
+// Top-level keys hold values of the flags of the root command and nested tables (or sections) hold values of the flags of the sub-commands.
+// JSON configs are decoded by the `encoding/json` package, while TOML, YAML and INI configs are read by built-in parsers
+// supporting scalar values, lists of scalar values and nested tables (or sections) only.
+// Values loaded later override values loaded earlier.
+// If the config can not be read or parsed, it returns `ErrorInvalidConfig` error.
+func (registry *Registry) LoadConfig(reader io.Reader, format ConfigFormat) error {
+
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return ErrorInvalidConfig{string(format), 0, err.Error()}
+	}
+
+	return registry.loadConfig(string(format), data, format)
+}
+
+// parse the config and merge its values into the registry
+func (registry *Registry) loadConfig(name string, data []byte, format ConfigFormat) error {
+
+	var tree configTree
+	var line int
+	var err error
+
+	switch format {
+	case ConfigJSON:
+		tree, err = parseJSONConfig(data)
+	case ConfigTOML:
+		tree, line, err = parseTOMLConfig(data)
+	case ConfigYAML:
+		tree, line, err = parseYAMLConfig(data)
+	case ConfigINI:
+		tree, line, err = parseINIConfig(data)
+	default:
+		return ErrorInvalidConfig{name, 0, "unsupported format"}
+	}
+
+	if err != nil {
+		return ErrorInvalidConfig{name, line, err.Error()}
+	}
+
+	if registry.config == nil {
+		registry.config = make(map[string]map[string][]string)
+	}
+
+	tree.flatten("", registry.config)
+
+	return nil
+}
+
+// setFlagsFromConfig sets values of the flags absent from the command-line arguments and the environment variables using the loaded config.
+// The `commandConfig` is the resolved copy of the registered `definition` command. The value of a flag is taken from the config section
+// of the command which defines the flag (a persistent flag inherited from a parent command is set in the section of the parent).
+// If a config value can not be converted to the type of the flag, it returns `ErrorInvalidValue` error.
+func (registry *Registry) setFlagsFromConfig(commandConfig *CommandConfig, definition *CommandConfig) error {
+
+	if len(registry.config) == 0 {
+		return nil
+	}
+
+	lineage := registry.lineage(definition)
+
+	for _, flagName := range commandConfig.flagNames() {
+		flag := commandConfig.Flags[flagName]
+
+		// command-line arguments and environment variables take precedence
+		if flag.IsSet {
+			continue
+		}
+
+		// the closest command defining the flag (the command itself or a parent with a persistent flag)
+		var values []string
+		for index := len(lineage) - 1; index >= 0; index-- {
+			if owner, ok := lineage[index].Flags[flagName]; ok && (index == len(lineage)-1 || owner.IsPersistent) {
+				values = registry.config[lineage[index].Path][flagName]
+				break
+			}
+		}
+
+		if len(values) == 0 {
+			continue
+		}
+
+		value := strings.Join(values, ",")
+
+		// value of a boolean flag is normalized to "true" or "false"
+		if flag.IsBoolean {
+			v, ok := parseBoolLiteral(value)
+			if !ok {
+				return ErrorInvalidValue{"--" + flag.Name, value, TypeBool, SourceConfig}
+			}
+
+			value = strconv.FormatBool(v)
+		} else {
+			for _, v := range values {
+				if !isValidValue(v, flag.Type) {
+					return ErrorInvalidValue{"--" + flag.Name, v, flag.Type, SourceConfig}
+				}
+			}
+		}
+
+		flag.Value = value
+		flag.IsSet, flag.Source = true, SourceConfig
+
+		// list values of a repeatable flag
+		if flag.IsRepeatable && !flag.IsBoolean {
+			flag.Values = append(make([]string, 0, len(values)), values...)
+		}
+	}
+
+	return nil
+}
+
+/*---------------------*/
+
+// strip a comment starting with one of the `markers` outside of quotes
+// a marker starts a comment only at the beginning of the line or after a whitespace (like `url = http://host/#top`)
+func stripComment(line string, markers string) string {
+
+	var quote rune
+	isEscaped := false
+
+	for index, char := range line {
+		switch {
+		case isEscaped:
+			isEscaped = false
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			} else if char == '\\' && quote == '"' {
+				isEscaped = true
+			}
+		case char == '"' || char == '\'':
+			quote = char
+		case strings.ContainsRune(markers, char):
+			if index == 0 || line[index-1] == ' ' || line[index-1] == '\t' {
+				return strings.TrimSpace(line[:index])
+			}
+		}
+	}
+
+	return strings.TrimSpace(line)
+}
+
+// split a value by `separator` outside of quotes and brackets
+func splitOutside(value string, separator rune) []string {
+
+	parts := make([]string, 0)
+	start, depth := 0, 0
+
+	var quote rune
+
+	for index, char := range value {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '"' || char == '\'':
+			quote = char
+		case char == '[' || char == '{':
+			depth++
+		case char == ']' || char == '}':
+			depth--
+		case char == separator && depth == 0:
+			parts = append(parts, value[start:index])
+			start = index + 1
+		}
+	}
+
+	return append(parts, value[start:])
+}
+
+// parse a scalar value (quoted or plain)
+func parseConfigScalar(value string) (string, error) {
+
+	value = strings.TrimSpace(value)
+
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return strconv.Unquote(value)
+	}
+
+	if len(value) >= 2 && strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	}
+
+	return value, nil
+}
+
+// parse a scalar value or a flow list (like `[a, b]`)
+func parseConfigValue(value string) (interface{}, error) {
+
+	value = strings.TrimSpace(value)
+
+	if strings.HasPrefix(value, "{") {
+		return nil, fmt.Errorf("inline tables are not supported")
+	}
+
+	if !strings.HasPrefix(value, "[") {
+		return parseConfigScalar(value)
+	}
+
+	if !strings.HasSuffix(value, "]") {
+		return nil, fmt.Errorf("unterminated list %s", value)
+	}
+
+	values := make([]string, 0)
+
+	for _, item := range splitOutside(value[1:len(value)-1], ',') {
+		if item = strings.TrimSpace(item); len(item) == 0 {
+			continue
+		}
+
+		if strings.HasPrefix(item, "[") || strings.HasPrefix(item, "{") {
+			return nil, fmt.Errorf("nested values are not supported")
+		}
+
+		scalar, err := parseConfigScalar(item)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, scalar)
+	}
+
+	return values, nil
+}
+
+// split a config key into the path of names
+func splitConfigKey(key string) ([]string, error) {
+
+	path := make([]string, 0)
+
+	for _, name := range splitOutside(key, '.') {
+		name, err := parseConfigScalar(name)
+		if err != nil || len(name) == 0 {
+			return nil, fmt.Errorf("invalid key %s", key)
+		}
+
+		path = append(path, name)
+	}
+
+	return path, nil
+}
+
+/*---------------------*/
+
+// parse a JSON config
+func parseJSONConfig(data []byte) (configTree, error) {
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document map[string]interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the document")
+	}
+
+	var convert func(object map[string]interface{}) (configTree, error)
+	convert = func(object map[string]interface{}) (configTree, error) {
+
+		tree := configTree{}
+
+		for key, value := range object {
+			switch value := value.(type) {
+			case nil:
+				continue
+			case map[string]interface{}:
+				subTree, err := convert(value)
+				if err != nil {
+					return nil, err
+				}
+
+				tree[key] = subTree
+			case []interface{}:
+				values := make([]string, 0, len(value))
+				for _, item := range value {
+					switch item.(type) {
+					case map[string]interface{}, []interface{}, nil:
+						return nil, fmt.Errorf("key %s contains nested values", key)
+					}
+
+					values = append(values, fmt.Sprint(item))
+				}
+
+				tree[key] = values
+			default:
+				tree[key] = fmt.Sprint(value)
+			}
+		}
+
+		return tree, nil
+	}
+
+	return convert(document)
+}
+
+// parse an INI config
+// sections (like `[remote.add]` or `[remote add]`) represent sub-commands
+func parseINIConfig(data []byte) (configTree, int, error) {
+
+	tree := configTree{}
+	section := tree
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for line := 1; scanner.Scan(); line++ {
+		text := stripComment(scanner.Text(), "#;")
+
+		if len(text) == 0 {
+			continue
+		}
+
+		// section
+		if strings.HasPrefix(text, "[") {
+			if !strings.HasSuffix(text, "]") {
+				return nil, line, fmt.Errorf("invalid section %s", text)
+			}
+
+			subTree, err := tree.subTree(strings.FieldsFunc(text[1:len(text)-1], func(char rune) bool {
+				return char == '.' || char == ' ' || char == '\t'
+			}))
+			if err != nil {
+				return nil, line, err
+			}
+
+			section = subTree
+			continue
+		}
+
+		// key-value pair
+		index := strings.IndexAny(text, "=:")
+		if index < 1 {
+			return nil, line, fmt.Errorf("invalid line %s", text)
+		}
+
+		value, err := parseConfigScalar(text[index+1:])
+		if err != nil {
+			return nil, line, err
+		}
+
+		section.set(strings.TrimSpace(text[:index]), value)
+	}
+
+	return tree, 0, scanner.Err()
+}
+
+// parse a TOML config
+// tables (like `[remote.add]`) and dotted keys represent sub-commands
+func parseTOMLConfig(data []byte) (configTree, int, error) {
+
+	tree := configTree{}
+	table := tree
+
+	// paths of the defined tables
+	tables := make(map[string]bool)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for line := 1; scanner.Scan(); line++ {
+		text := stripComment(scanner.Text(), "#")
+
+		if len(text) == 0 {
+			continue
+		}
+
+		// table
+		if strings.HasPrefix(text, "[") {
+			if strings.HasPrefix(text, "[[") {
+				return nil, line, fmt.Errorf("arrays of tables are not supported")
+			}
+
+			if !strings.HasSuffix(text, "]") {
+				return nil, line, fmt.Errorf("invalid table %s", text)
+			}
+
+			path, err := splitConfigKey(text[1 : len(text)-1])
+			if err != nil {
+				return nil, line, err
+			}
+
+			if tables[fmt.Sprintf("%q", path)] {
+				return nil, line, fmt.Errorf("duplicate table %s", text)
+			}
+
+			tables[fmt.Sprintf("%q", path)] = true
+
+			if table, err = tree.subTree(path); err != nil {
+				return nil, line, err
+			}
+
+			continue
+		}
+
+		// key-value pair
+		parts := splitOutside(text, '=')
+		if len(parts) < 2 {
+			return nil, line, fmt.Errorf("invalid line %s", text)
+		}
+
+		key, value := parts[0], strings.TrimSpace(text[len(parts[0])+1:])
+
+		// multi-line list
+		startLine := line
+		for strings.HasPrefix(value, "[") && strings.Count(value, "[") > strings.Count(value, "]") && scanner.Scan() {
+			line++
+			value += " " + stripComment(scanner.Text(), "#")
+		}
+
+		path, err := splitConfigKey(key)
+		if err != nil {
+			return nil, startLine, err
+		}
+
+		parsed, err := parseConfigValue(value)
+		if err != nil {
+			return nil, startLine, err
+		}
+
+		subTree, err := table.subTree(path[:len(path)-1])
+		if err != nil {
+			return nil, startLine, err
+		}
+
+		if _, ok := subTree[path[len(path)-1]]; ok {
+			return nil, startLine, fmt.Errorf("duplicate key %s", strings.TrimSpace(key))
+		}
+
+		subTree[path[len(path)-1]] = parsed
+	}
+
+	return tree, 0, scanner.Err()
+}
+
+// a block of YAML mappings with the indentation of its keys
+type yamlBlock struct {
+	indent int
+	tree   configTree
+}
+
+// regular expression of a YAML mapping
+var yamlMapping = regexp.MustCompile(`^("[^"]*"|'[^']*'|[^:#]+?)\s*:(\s+(.*))?$`)
+
+// parse a YAML scalar value or a flow sequence, rejecting the syntax outside of the supported subset
+func parseYAMLValue(value string) (interface{}, error) {
+
+	value = strings.TrimSpace(value)
+
+	items := []string{value}
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		items = splitOutside(value[1:len(value)-1], ',')
+	}
+
+	for _, item := range items {
+		switch item = strings.TrimSpace(item); {
+		case item == "~" || item == "null" || item == "Null" || item == "NULL":
+			return nil, fmt.Errorf("null values are not supported")
+		case strings.HasPrefix(item, "&") || strings.HasPrefix(item, "*") || strings.HasPrefix(item, "!"):
+			return nil, fmt.Errorf("anchors, aliases and tags are not supported")
+		case strings.HasPrefix(item, "|") || strings.HasPrefix(item, ">"):
+			return nil, fmt.Errorf("multi-line scalars are not supported")
+		case strings.HasPrefix(item, "@") || strings.HasPrefix(item, "`") || strings.HasPrefix(item, "%"):
+			return nil, fmt.Errorf("invalid value %s", item)
+		}
+	}
+
+	return parseConfigValue(value)
+}
+
+// parse a YAML config
+// nested mappings represent sub-commands
+func parseYAMLConfig(data []byte) (configTree, int, error) {
+
+	tree := configTree{}
+	blocks := []*yamlBlock{{indent: 0, tree: tree}}
+
+	// key without a value (followed by a nested mapping or a list)
+	var pendingTree configTree
+	pendingKey, pendingIndent := "", 0
+
+	// key of the list being collected
+	var listTree configTree
+	listKey, listIndent := "", 0
+
+	// if a document has started
+	isStarted := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for line := 1; scanner.Scan(); line++ {
+		raw := strings.TrimRight(scanner.Text(), " \t\r")
+		text := stripComment(raw, "#")
+
+		if len(text) == 0 {
+			continue
+		}
+
+		// document markers (a single document is supported)
+		if text == "---" || text == "..." || strings.HasPrefix(text, "--- ") || strings.HasPrefix(text, "%") {
+			if text != "---" || isStarted {
+				return nil, line, fmt.Errorf("multiple documents and directives are not supported")
+			}
+
+			isStarted = true
+			continue
+		}
+
+		isStarted = true
+
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+		if strings.HasPrefix(raw[indent:], "\t") {
+			return nil, line, fmt.Errorf("tabs are not allowed in the indentation")
+		}
+
+		isListItem := strings.HasPrefix(text, "- ") || text == "-"
+
+		// value of the pending key
+		if len(pendingKey) != 0 {
+			switch {
+			case isListItem && indent >= pendingIndent:
+				pendingTree[pendingKey] = make([]string, 0)
+				listTree, listKey, listIndent = pendingTree, pendingKey, indent
+			case !isListItem && indent > pendingIndent:
+				subTree := configTree{}
+				pendingTree[pendingKey] = subTree
+				blocks = append(blocks, &yamlBlock{indent: indent, tree: subTree})
+			default:
+				pendingTree[pendingKey] = ""
+			}
+
+			pendingKey = ""
+		}
+
+		// list item
+		if isListItem {
+			if len(listKey) == 0 || indent != listIndent {
+				return nil, line, fmt.Errorf("unexpected list item %s", text)
+			}
+
+			value, err := parseYAMLValue(strings.TrimPrefix(text, "-"))
+			if err != nil {
+				return nil, line, err
+			}
+
+			scalar, ok := value.(string)
+			if !ok {
+				return nil, line, fmt.Errorf("nested values are not supported")
+			}
+
+			listTree[listKey] = append(listTree[listKey].([]string), scalar)
+			continue
+		}
+
+		listKey = ""
+
+		// close blocks with greater indentation
+		for len(blocks) > 1 && indent < blocks[len(blocks)-1].indent {
+			blocks = blocks[:len(blocks)-1]
+		}
+
+		block := blocks[len(blocks)-1]
+		if indent != block.indent {
+			return nil, line, fmt.Errorf("invalid indentation of %s", text)
+		}
+
+		// mapping
+		matches := yamlMapping.FindStringSubmatch(text)
+		if matches == nil {
+			return nil, line, fmt.Errorf("invalid line %s", text)
+		}
+
+		key, err := parseConfigScalar(matches[1])
+		if err != nil {
+			return nil, line, err
+		}
+
+		if _, ok := block.tree[key]; ok {
+			return nil, line, fmt.Errorf("duplicate key %s", key)
+		}
+
+		if len(strings.TrimSpace(matches[3])) == 0 {
+			pendingTree, pendingKey, pendingIndent = block.tree, key, indent
+			continue
+		}
+
+		value, err := parseYAMLValue(matches[3])
+		if err != nil {
+			return nil, line, err
+		}
+
+		block.tree[key] = value
+	}
+
+	if len(pendingKey) != 0 {
+		pendingTree[pendingKey] = ""
+	}
+
+	return tree, 0, scanner.Err()
+}
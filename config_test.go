@@ -0,0 +1,186 @@
+package clapper
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// test values of flags loaded from config files
+func TestConfigFiles(t *testing.T) {
+
+	for _, path := range []string{"testdata/config.json", "testdata/config.toml", "testdata/config.yaml", "testdata/config.ini"} {
+		// create a new registry
+		registry := NewRegistry()
+		registry.EnvPrefix = "CLAPPER_CONFIG_"
+
+		rootCommand, _ := registry.Register("")
+		portFlag, _ := rootCommand.AddTypedFlag("port", "p", TypeInt, "8080")
+		portFlag.EnvVars = []string{"PORT"}
+		rootCommand.AddFlag("debug", "d", true, "")
+		tagsFlag, _ := rootCommand.AddFlag("tags", "t", false, "")
+		tagsFlag.IsRepeatable = true
+
+		remoteCommand, _ := registry.Register("remote")
+		upstreamFlag, _ := remoteCommand.AddFlag("upstream", "u", false, "")
+		upstreamFlag.IsPersistent = true
+		addCommand, _ := remoteCommand.Register("add")
+		addCommand.AddArg("name", "")
+		addCommand.AddFlag("branch", "b", false, "master")
+
+		if err := registry.LoadConfigFile(path); err != nil {
+			t.Fatalf("%s: unexpected error: %v", path, err)
+		}
+
+		// values of the root command
+		command, err := registry.Parse([]string{})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", path, err)
+		}
+
+		if port, _ := command.Flags["port"].Int(); port != 9090 {
+			t.Errorf("%s: expected port 9090, got %d", path, port)
+		}
+
+		if command.Flags["debug"].Value != "true" {
+			t.Errorf("%s: expected debug \"true\", got %#v", path, command.Flags["debug"].Value)
+		}
+
+		if tags := command.Flags["tags"].Values; !reflect.DeepEqual(tags, []string{"alpha", "beta"}) {
+			t.Errorf("%s: expected tags [alpha beta], got %#v", path, tags)
+		}
+
+		// values of a nested sub-command
+		command, err = registry.Parse([]string{"remote", "add", "origin"})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", path, err)
+		}
+
+		if command.Flags["branch"].Value != "main" {
+			t.Errorf("%s: expected branch \"main\", got %#v", path, command.Flags["branch"].Value)
+		}
+
+		// a persistent flag inherited from a parent command is set in the section of the parent
+		if result, err := registry.ParseArgs([]string{"remote", "add", "origin"}); err != nil || result.Flags["upstream"].Value != "origin" {
+			t.Errorf("%s: expected inherited upstream \"origin\", got %#v (%v)", path, result, err)
+		}
+	}
+}
+
+// test precedence of config values
+func TestConfigPrecedence(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+	registry.EnvPrefix = "CLAPPER_CONFIG_"
+
+	rootCommand, _ := registry.Register("")
+	portFlag, _ := rootCommand.AddTypedFlag("port", "p", TypeInt, "8080")
+	portFlag.EnvVars = []string{"PORT"}
+
+	remoteCommand, _ := registry.Register("remote")
+	addCommand, _ := remoteCommand.Register("add")
+	addCommand.AddArg("name", "")
+	addCommand.AddFlag("branch", "b", false, "master")
+
+	if err := registry.LoadConfig(strings.NewReader("port = 9090\n[remote.add]\nbranch = \"main\"\n"), ConfigTOML); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// environment variables take precedence over config files
+	os.Setenv("CLAPPER_CONFIG_PORT", "7070")
+	defer os.Unsetenv("CLAPPER_CONFIG_PORT")
+
+	command, err := registry.Parse([]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if command.Flags["port"].Value != "7070" {
+		t.Errorf("expected port \"7070\" from environment, got %#v", command.Flags["port"].Value)
+	}
+
+	// command-line arguments take precedence over config files
+	command, err = registry.Parse([]string{"remote", "add", "origin", "--branch", "dev"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if command.Flags["branch"].Value != "dev" {
+		t.Errorf("expected branch \"dev\", got %#v", command.Flags["branch"].Value)
+	}
+}
+
+// test invalid config files and values
+func TestInvalidConfig(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	rootCommand, _ := registry.Register("")
+	rootCommand.AddTypedFlag("port", "p", TypeInt, "8080")
+
+	// unsupported file extension
+	if err := registry.LoadConfigFile("testdata/config.xml"); err != (ErrorInvalidConfig{"testdata/config.xml", 0, "unsupported file extension"}) {
+		t.Errorf("expected invalid config error, got %#v", err)
+	}
+
+	// syntax errors with line numbers
+	if err := registry.LoadConfig(strings.NewReader("port: 1\n  debug: true\n"), ConfigYAML); err != (ErrorInvalidConfig{"yaml", 2, "invalid indentation of debug: true"}) {
+		t.Errorf("expected invalid config error, got %#v", err)
+	}
+
+	if err := registry.LoadConfig(strings.NewReader("[remote\n"), ConfigINI); err != (ErrorInvalidConfig{"ini", 1, "invalid section [remote"}) {
+		t.Errorf("expected invalid config error, got %#v", err)
+	}
+
+	// syntax outside of the supported subsets
+	errs := []struct {
+		config   string
+		format   ConfigFormat
+		expected ErrorInvalidConfig
+	}{
+		{`{"port": 1} {"port": 2}`, ConfigJSON, ErrorInvalidConfig{"json", 0, "unexpected data after the document"}},
+		{"port = 1\nport = 2\n", ConfigTOML, ErrorInvalidConfig{"toml", 2, "duplicate key port"}},
+		{"[remote]\nport = 1\n[remote]\n", ConfigTOML, ErrorInvalidConfig{"toml", 3, "duplicate table [remote]"}},
+		{"port: ~\n", ConfigYAML, ErrorInvalidConfig{"yaml", 1, "null values are not supported"}},
+		{"port: &port 1\n", ConfigYAML, ErrorInvalidConfig{"yaml", 1, "anchors, aliases and tags are not supported"}},
+		{"tags:\n  - *tag\n", ConfigYAML, ErrorInvalidConfig{"yaml", 2, "anchors, aliases and tags are not supported"}},
+		{"tags: [a, !!str b]\n", ConfigYAML, ErrorInvalidConfig{"yaml", 1, "anchors, aliases and tags are not supported"}},
+		{"port: |\n  1\n", ConfigYAML, ErrorInvalidConfig{"yaml", 1, "multi-line scalars are not supported"}},
+		{"remote:\n\tport: 1\n", ConfigYAML, ErrorInvalidConfig{"yaml", 2, "tabs are not allowed in the indentation"}},
+		{"port: 1\nport: 2\n", ConfigYAML, ErrorInvalidConfig{"yaml", 2, "duplicate key port"}},
+		{"port: 1\n---\nport: 2\n", ConfigYAML, ErrorInvalidConfig{"yaml", 2, "multiple documents and directives are not supported"}},
+	}
+
+	for _, e := range errs {
+		if err := registry.LoadConfig(strings.NewReader(e.config), e.format); err != e.expected {
+			t.Errorf("expected %#v, got %#v", e.expected, err)
+		}
+	}
+
+	// a marker starts a comment only after a whitespace
+	urlRegistry := NewRegistry()
+	urlCommand, _ := urlRegistry.Register("")
+	urlCommand.AddFlag("url", "", false, "")
+
+	if err := urlRegistry.LoadConfig(strings.NewReader("url = http://host/a;b#c ; comment\n"), ConfigINI); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if command, err := urlRegistry.Parse([]string{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if value := command.Flags["url"].Value; value != "http://host/a;b#c" {
+		t.Errorf("unexpected url value: %s", value)
+	}
+
+	// invalid value of a typed flag
+	if err := registry.LoadConfig(strings.NewReader(`{"port": "http"}`), ConfigJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := registry.Parse([]string{}); err != (ErrorInvalidValue{"--port", "http", TypeInt, SourceConfig}) {
+		t.Errorf("expected invalid value error, got %#v", err)
+	}
+}
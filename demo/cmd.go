@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/thatisuday/clapper"
+	clapper "github.com/thatisuday/clapper/v2"
 )
 
 func main() {
@@ -42,6 +42,12 @@ func main() {
 
 	/*----------------*/
 
+	// print the usage text if the help is requested
+	if err, ok := err.(clapper.ErrorHelpRequested); ok {
+		fmt.Print(err.Usage)
+		return
+	}
+
 	// check for error
 	if err != nil {
 		fmt.Printf("error => %#v\n", err)
@@ -0,0 +1,170 @@
+// MIT License
+
+// Copyright (c) 2020 Uday Hiwarale
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package clapper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// HandlerFunc represents a function attached to a command which is invoked by the `Registry.Execute` method
+// with the parse result of the command-line arguments.
+type HandlerFunc func(ctx context.Context, result *ParseResult) error
+
+// AfterHookFunc represents a function invoked by the `Registry.Execute` method after the handler of a command,
+// with the parse result and the error returned by the handler (nil if the handler succeeds).
+type AfterHookFunc func(ctx context.Context, result *ParseResult, err error) error
+
+// MiddlewareFunc represents a function which wraps the run step of a command (the before hooks, the handler and the after hooks)
+// invoked by the `Registry.Execute` method, like `func(next HandlerFunc) HandlerFunc`.
+type MiddlewareFunc func(next HandlerFunc) HandlerFunc
+
+// ErrorMissingHandler represents an error when the command matched by the command-line arguments has no handler.
+// The `Name` field contains the path of the command.
+type ErrorMissingHandler struct {
+	Name string
+}
+
+func (e ErrorMissingHandler) Error() string {
+	return fmt.Sprintf("no handler registered for command %s", strconv.Quote(e.Name))
+}
+
+/*---------------------*/
+
+// output returns the writer of the text printed by the `Execute` method.
+func (registry *Registry) output() io.Writer {
+	if registry.Output == nil {
+		return os.Stdout
+	}
+
+	return registry.Output
+}
+
+// lineage returns the command and its parent commands, starting from the root command (if registered).
+func (registry *Registry) lineage(commandConfig *CommandConfig) []*CommandConfig {
+
+	lineage := make([]*CommandConfig, 0)
+	for command := commandConfig; command != nil; command = command.parent {
+		lineage = append([]*CommandConfig{command}, lineage...)
+	}
+
+	// the root command is the parent of the top-level commands
+	if rootCommandConfig, ok := registry.Commands[""]; ok && lineage[0] != rootCommandConfig {
+		lineage = append([]*CommandConfig{rootCommandConfig}, lineage...)
+	}
+
+	return lineage
+}
+
+// run step of a command wrapped by the middlewares
+// hooks and middlewares of the registry come first, followed by the hooks and middlewares of the root command and the parent commands
+func (registry *Registry) run(commandConfig *CommandConfig) HandlerFunc {
+
+	lineage := registry.lineage(commandConfig)
+
+	beforeHooks := append(make([]HandlerFunc, 0), registry.BeforeHooks...)
+	afterHooks := append(make([]AfterHookFunc, 0), registry.AfterHooks...)
+	middlewares := append(make([]MiddlewareFunc, 0), registry.Middlewares...)
+
+	for _, command := range lineage {
+		beforeHooks = append(beforeHooks, command.BeforeHooks...)
+		afterHooks = append(afterHooks, command.AfterHooks...)
+		middlewares = append(middlewares, command.Middlewares...)
+	}
+
+	handler := func(ctx context.Context, result *ParseResult) error {
+
+		for _, hook := range beforeHooks {
+			if err := hook(ctx, result); err != nil {
+				return err
+			}
+		}
+
+		err := commandConfig.Handler(ctx, result)
+
+		// after hooks of the command run first, an error returned by a hook replaces the error of the handler
+		for index := len(afterHooks) - 1; index >= 0; index-- {
+			if hookErr := afterHooks[index](ctx, result, err); hookErr != nil {
+				err = hookErr
+			}
+		}
+
+		return err
+	}
+
+	// the first middleware is the outermost
+	for index := len(middlewares) - 1; index >= 0; index-- {
+		handler = middlewares[index](handler)
+	}
+
+	return handler
+}
+
+// Execute method parses command-line arguments using the `ParseArgs` method and invokes the `Handler` of the matched command
+// with the `ctx` context and the parse result. The error returned by the handler is returned, unless an after hook replaces it.
+// The `BeforeHooks` are invoked before the handler with the same arguments as the handler. The `AfterHooks` are invoked after the handler
+// (whether it succeeds or fails) with the error of the handler, and a non-nil error returned by an after hook replaces it.
+// The `Middlewares` wrap the hooks and the handler.
+// Hooks and middlewares of the registry apply to all commands and hooks and middlewares of a command apply to its sub-commands
+// (hooks and middlewares of the root command apply to all commands).
+// Before hooks and middlewares run from the registry (and the root command) to the matched command, and after hooks run in the reverse order.
+// If a before hook returns an error, the handler and the after hooks are skipped and the error is returned (a middleware can observe it).
+// When the help is requested, the usage text of the command is printed to the `Output` of the registry and nil is returned.
+// Likewise, the completion candidates requested by the hidden `__complete` command are printed one per line.
+// Other errors returned by the `ParseArgs` method are returned as they are.
+// If the matched command has no handler, it returns `ErrorMissingHandler` error.
+func (registry *Registry) Execute(ctx context.Context, values []string) error {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	result, err := registry.ParseArgs(values)
+	if err, ok := err.(ErrorHelpRequested); ok {
+		_, err := fmt.Fprint(registry.output(), err.Usage)
+		return err
+	}
+
+	if err, ok := err.(ErrorCompletionRequested); ok {
+		for _, candidate := range err.Candidates {
+			if _, err := fmt.Fprintln(registry.output(), candidate); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if result.Command.Handler == nil {
+		return ErrorMissingHandler{result.Command.Path}
+	}
+
+	return registry.run(result.Command)(ctx, result)
+}
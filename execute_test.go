@@ -0,0 +1,235 @@
+package clapper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// key of a context value used to test handlers
+type executeKey struct{}
+
+// test command handlers invoked by Execute
+func TestExecute(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	rootCommand, _ := registry.Register("")
+	rootCommand.AddFlag("verbose", "v", true, "")
+
+	remoteCommand, _ := registry.Register("remote")
+	addCommand, _ := remoteCommand.Register("add")
+	addCommand.AddArg("name", "")
+
+	// invoked handlers
+	invoked := make([]string, 0)
+
+	rootCommand.Handler = func(ctx context.Context, result *ParseResult) error {
+		invoked = append(invoked, "root:"+result.Flags["verbose"].Effective())
+		return nil
+	}
+
+	errFailed := errors.New("failed")
+
+	addCommand.Handler = func(ctx context.Context, result *ParseResult) error {
+		invoked = append(invoked, result.Command.Path+":"+result.Args["name"].Value+":"+ctx.Value(executeKey{}).(string))
+		return errFailed
+	}
+
+	ctx := context.WithValue(context.Background(), executeKey{}, "ctx")
+
+	if err := registry.Execute(ctx, []string{"-v"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// error returned by the handler
+	if err := registry.Execute(ctx, []string{"remote", "add", "origin"}); err != errFailed {
+		t.Errorf("expected handler error, got %#v", err)
+	}
+
+	if len(invoked) != 2 || invoked[0] != "root:true" || invoked[1] != "remote add:origin:ctx" {
+		t.Errorf("unexpected handler calls: %#v", invoked)
+	}
+
+	// command without a handler
+	if err := registry.Execute(ctx, []string{"remote"}); err != (ErrorMissingHandler{"remote"}) {
+		t.Errorf("expected missing handler error, got %#v", err)
+	}
+
+	// parse errors
+	if err := registry.Execute(ctx, []string{"--unknown"}); !reflect.DeepEqual(err, ErrorUnknownFlag{"--unknown", nil}) {
+		t.Errorf("expected unknown flag error, got %#v", err)
+	}
+
+	// usage text is printed to the output
+	output := &bytes.Buffer{}
+	registry.Output = output
+
+	if err := registry.Execute(ctx, []string{"remote", "add", "--help"}); err != nil || output.String() != registry.Help(programName(), addCommand) || len(invoked) != 2 {
+		t.Errorf("expected usage text, got %q (%v)", output.String(), err)
+	}
+
+	// completion candidates are printed to the output
+	output.Reset()
+	remoteCommand.Register("remove")
+
+	if err := registry.Execute(ctx, []string{"__complete", "remote", ""}); err != nil || output.String() != "add\nremove\n" || len(invoked) != 2 {
+		t.Errorf("expected completion candidates, got %q (%v)", output.String(), err)
+	}
+}
+
+// test order of hooks and middlewares
+func TestHooks(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	rootCommand, _ := registry.Register("")
+	remoteCommand, _ := registry.Register("remote")
+	addCommand, _ := remoteCommand.Register("add")
+	addCommand.AddFlag("token", "t", false, "")
+
+	// order of the calls
+	calls := make([]string, 0)
+
+	hook := func(name string) HandlerFunc {
+		return func(ctx context.Context, result *ParseResult) error {
+			calls = append(calls, name+":"+result.Command.Path)
+			return nil
+		}
+	}
+
+	afterHook := func(name string) AfterHookFunc {
+		return func(ctx context.Context, result *ParseResult, err error) error {
+			calls = append(calls, fmt.Sprintf("%s:%s:%v", name, result.Command.Path, err))
+			return nil
+		}
+	}
+
+	middleware := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(ctx context.Context, result *ParseResult) error {
+				calls = append(calls, name+":start")
+				err := next(ctx, result)
+				calls = append(calls, name+":end")
+				return err
+			}
+		}
+	}
+
+	registry.BeforeHooks = []HandlerFunc{hook("registry-before")}
+	registry.AfterHooks = []AfterHookFunc{afterHook("registry-after")}
+	registry.Middlewares = []MiddlewareFunc{middleware("registry")}
+
+	// hooks and middlewares of the root command apply to the sub-commands
+	rootCommand.BeforeHooks = []HandlerFunc{hook("root-before")}
+	rootCommand.AfterHooks = []AfterHookFunc{afterHook("root-after")}
+	rootCommand.Middlewares = []MiddlewareFunc{middleware("root")}
+
+	remoteCommand.BeforeHooks = []HandlerFunc{hook("remote-before")}
+	remoteCommand.AfterHooks = []AfterHookFunc{afterHook("remote-after")}
+	remoteCommand.Middlewares = []MiddlewareFunc{middleware("remote")}
+
+	// an auth check
+	errUnauthorized := errors.New("unauthorized")
+
+	addCommand.BeforeHooks = []HandlerFunc{func(ctx context.Context, result *ParseResult) error {
+		if result.Flags["token"].Value != "secret" {
+			return errUnauthorized
+		}
+
+		return nil
+	}}
+
+	addCommand.Handler = hook("handler")
+
+	if err := registry.Execute(context.Background(), []string{"remote", "add", "-t", "secret"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"registry:start",
+		"root:start",
+		"remote:start",
+		"registry-before:remote add",
+		"root-before:remote add",
+		"remote-before:remote add",
+		"handler:remote add",
+		"remote-after:remote add:<nil>",
+		"root-after:remote add:<nil>",
+		"registry-after:remote add:<nil>",
+		"remote:end",
+		"root:end",
+		"registry:end",
+	}
+
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected calls %#v, got %#v", expected, calls)
+	}
+
+	// after hooks receive the error of the handler and can replace it
+	errFailed, errReported := errors.New("failed"), errors.New("reported")
+
+	addCommand.Handler = func(ctx context.Context, result *ParseResult) error {
+		calls = append(calls, "handler:"+result.Command.Path)
+		return errFailed
+	}
+
+	remoteCommand.AfterHooks = []AfterHookFunc{func(ctx context.Context, result *ParseResult, err error) error {
+		calls = append(calls, fmt.Sprintf("remote-after:%s:%v", result.Command.Path, err))
+		return errReported
+	}}
+
+	calls = make([]string, 0)
+
+	if err := registry.Execute(context.Background(), []string{"remote", "add", "-t", "secret"}); err != errReported {
+		t.Errorf("expected after hook error, got %#v", err)
+	}
+
+	expected = []string{
+		"registry:start",
+		"root:start",
+		"remote:start",
+		"registry-before:remote add",
+		"root-before:remote add",
+		"remote-before:remote add",
+		"handler:remote add",
+		"remote-after:remote add:failed",
+		"root-after:remote add:reported",
+		"registry-after:remote add:reported",
+		"remote:end",
+		"root:end",
+		"registry:end",
+	}
+
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected calls %#v, got %#v", expected, calls)
+	}
+
+	// a failed before hook stops the execution
+	calls = make([]string, 0)
+
+	if err := registry.Execute(context.Background(), []string{"remote", "add"}); err != errUnauthorized {
+		t.Errorf("expected hook error, got %#v", err)
+	}
+
+	expected = []string{
+		"registry:start",
+		"root:start",
+		"remote:start",
+		"registry-before:remote add",
+		"root-before:remote add",
+		"remote-before:remote add",
+		"remote:end",
+		"root:end",
+		"registry:end",
+	}
+
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected calls %#v, got %#v", expected, calls)
+	}
+}
@@ -1,3 +1,3 @@
-module github.com/thatisuday/clapper
+module github.com/thatisuday/clapper/v2
 
 go 1.13
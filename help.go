@@ -0,0 +1,294 @@
+// MIT License
+
+// Copyright (c) 2020 Uday Hiwarale
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package clapper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// name of the program (used in the usage text)
+func programName() string {
+	return filepath.Base(os.Args[0])
+}
+
+// check if value is a built-in help flag (`--help` or `-h`) which is not overridden by a registered flag
+func isHelpFlag(value string, commandConfig *CommandConfig) bool {
+	if value == "--help" {
+		_, ok := commandConfig.Flags["help"]
+		return !ok
+	}
+
+	if value == "-h" {
+		_, ok := commandConfig.flagsShort["h"]
+		return !ok
+	}
+
+	return false
+}
+
+// first line of a description
+func summary(description string) string {
+	return strings.TrimSpace(strings.SplitN(strings.TrimSpace(description), "\n", 2)[0])
+}
+
+// write a section of two-column rows with aligned descriptions
+func writeSection(builder *strings.Builder, title string, rows [][2]string) {
+
+	if len(rows) == 0 {
+		return
+	}
+
+	// width of the first column
+	width := 0
+	for _, row := range rows {
+		if len(row[0]) > width {
+			width = len(row[0])
+		}
+	}
+
+	fmt.Fprintf(builder, "\n%s:\n", title)
+
+	for _, row := range rows {
+		if len(row[1]) == 0 {
+			fmt.Fprintf(builder, "  %s\n", row[0])
+		} else {
+			fmt.Fprintf(builder, "  %-*s  %s\n", width, row[0], row[1])
+		}
+	}
+}
+
+/*---------------------*/
+
+// ErrorHelpRequested represents an error when command-line arguments contain the built-in `--help` or `-h` flag.
+// The `Name` field contains the path of the command and the `Usage` field contains the usage text of the command.
+type ErrorHelpRequested struct {
+	Name  string
+	Usage string
+}
+
+func (e ErrorHelpRequested) Error() string {
+	return fmt.Sprintf("help requested for command %s", strconv.Quote(e.Name))
+}
+
+/*---------------------*/
+
+// Help method returns the usage text of a command registered in the registry.
+// The `program` argument is the name of the program used in the usage line.
+// If the `commandConfig` is `nil`, the usage text lists the commands registered in the registry.
+// The flags of a command include the persistent flags inherited from its parent commands.
+func (registry *Registry) Help(program string, commandConfig *CommandConfig) string {
+
+	builder := &strings.Builder{}
+
+	// flags of the command including the inherited persistent flags
+	if commandConfig != nil {
+		commandConfig = registry.resolve(commandConfig)
+	}
+
+	// sub-commands of the command
+	var commands map[string]*CommandConfig
+	if commandConfig == nil || len(commandConfig.Path) == 0 {
+		commands = registry.Commands
+	} else {
+		commands = commandConfig.SubCommands
+	}
+
+	commandNames := make([]string, 0, len(commands))
+	for commandName := range commands {
+		if len(commandName) != 0 {
+			commandNames = append(commandNames, commandName)
+		}
+	}
+
+	sort.Strings(commandNames)
+
+	// usage line
+	usage := []string{"Usage:", program}
+
+	if commandConfig == nil {
+		usage = append(usage, "<command>")
+	} else {
+		if len(commandConfig.Path) != 0 {
+			usage = append(usage, commandConfig.Path)
+		}
+
+		if len(commandNames) > 0 {
+			if len(commandConfig.Path) == 0 {
+				usage = append(usage, "[<command>]")
+			} else {
+				usage = append(usage, "<command>")
+			}
+		}
+
+		usage = append(usage, "[flags]")
+
+		for _, argName := range commandConfig.ArgNames {
+			usage = append(usage, commandConfig.Args[argName].usage())
+		}
+	}
+
+	builder.WriteString(strings.Join(usage, " ") + "\n")
+
+	if commandConfig == nil {
+		commandConfig = newCommandConfig("", nil)
+	}
+
+	// description of the command
+	if len(commandConfig.Description) != 0 {
+		fmt.Fprintf(builder, "\n%s\n", strings.TrimSpace(commandConfig.Description))
+	}
+
+	// list of sub-commands (with aliases)
+	rows := make([][2]string, 0)
+	for _, commandName := range commandNames {
+		names := append([]string{commandName}, commands[commandName].Aliases...)
+		rows = append(rows, [2]string{strings.Join(names, ", "), summary(commands[commandName].Description)})
+	}
+
+	writeSection(builder, "Commands", rows)
+
+	// list of arguments
+	rows = make([][2]string, 0)
+	for _, argName := range commandConfig.ArgNames {
+		arg := commandConfig.Args[argName]
+
+		name := argName
+		if arg.IsVariadic {
+			name += "..."
+		}
+
+		rows = append(rows, [2]string{name, describe(arg.Description, arg.DefaultValue, arg.IsRequired)})
+	}
+
+	writeSection(builder, "Arguments", rows)
+
+	// list of flags
+	rows = make([][2]string, 0)
+	for _, flagName := range commandConfig.flagNames() {
+		flag := commandConfig.Flags[flagName]
+
+		// default value of a boolean flag is implied
+		defaultValue := flag.DefaultValue
+		if flag.IsBoolean {
+			defaultValue = ""
+		}
+
+		description := describe(flag.Description, defaultValue, flag.IsRequired)
+
+		// bound environment variables
+		if len(flag.EnvVars) > 0 {
+			envNames := make([]string, 0, len(flag.EnvVars))
+			for _, envName := range flag.EnvVars {
+				envNames = append(envNames, "$"+registry.EnvPrefix+envName)
+			}
+
+			description = strings.TrimSpace(fmt.Sprintf("%s (env: %s)", description, strings.Join(envNames, ", ")))
+		}
+
+		rows = append(rows, [2]string{flag.usage(), description})
+	}
+
+	// built-in help flag
+	if _, ok := commandConfig.Flags["help"]; !ok {
+		if _, ok := commandConfig.flagsShort["h"]; ok {
+			rows = append(rows, [2]string{"    --help", "show help"})
+		} else {
+			rows = append(rows, [2]string{"-h, --help", "show help"})
+		}
+	}
+
+	writeSection(builder, "Flags", rows)
+
+	return builder.String()
+}
+
+// description of a flag or an argument with its default value
+func describe(description string, defaultValue string, isRequired bool) string {
+
+	parts := make([]string, 0)
+
+	if len(description) != 0 {
+		parts = append(parts, summary(description))
+	}
+
+	if isRequired {
+		parts = append(parts, "(required)")
+	} else if len(defaultValue) != 0 {
+		parts = append(parts, fmt.Sprintf("(default: %s)", defaultValue))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// usage of the argument in the usage line
+func (arg *Arg) usage() string {
+
+	usage := fmt.Sprintf("<%s>", arg.Name)
+	if arg.IsVariadic {
+		usage += "..."
+	}
+
+	if !arg.IsRequired {
+		usage = fmt.Sprintf("[%s]", usage)
+	}
+
+	return usage
+}
+
+// usage of the flag in the list of flags
+func (flag *Flag) usage() string {
+
+	// short name of the flag
+	usage := "    "
+	if len(flag.ShortName) != 0 {
+		usage = fmt.Sprintf("-%s, ", flag.ShortName)
+	}
+
+	// long name of the flag
+	if flag.IsInverted {
+		usage += "--no-" + flag.Name
+	} else {
+		usage += "--" + flag.Name
+	}
+
+	// value of the flag
+	if !flag.IsBoolean {
+		metaVar := flag.MetaVar
+		if len(metaVar) == 0 {
+			metaVar = "value"
+		}
+
+		if flag.IsValueOptional {
+			usage += fmt.Sprintf("[=<%s>]", metaVar)
+		} else {
+			usage += fmt.Sprintf(" <%s>", metaVar)
+		}
+	}
+
+	return usage
+}
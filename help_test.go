@@ -0,0 +1,110 @@
+package clapper
+
+import (
+	"testing"
+)
+
+// test usage text of commands
+func TestHelp(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	rootCommand, _ := registry.Register("")
+	rootCommand.Description = "Manage remote repositories."
+	rootCommand.AddFlag("verbose", "v", true, "")
+	configFlag, _ := rootCommand.AddFlag("config", "c", false, "")
+	configFlag.Description = "config file"
+	configFlag.IsPersistent = true
+
+	remoteCommand, _ := registry.Register("remote")
+	remoteCommand.Description = "Manage remotes.\nRemotes are tracked repositories."
+
+	addCommand, _ := remoteCommand.Register("add")
+	addCommand.Description = "Add a remote."
+	nameArg, _ := addCommand.AddArg("name", "")
+	nameArg.Description = "name of the remote"
+	nameArg.IsRequired = true
+	addCommand.AddArg("urls...", "")
+	branchFlag, _ := addCommand.AddFlag("branch", "b", false, "master")
+	branchFlag.Description = "branch to track"
+	branchFlag.MetaVar = "name"
+	addCommand.AddFlag("no-fetch", "", true, "")
+	addCommand.AddFlag("host", "h", false, "")
+
+	// usage text of commands
+	usages := map[*CommandConfig]string{
+		rootCommand: `Usage: tool [<command>] [flags]
+
+Manage remote repositories.
+
+Commands:
+  remote  Manage remotes.
+
+Flags:
+  -c, --config <value>  config file
+  -v, --verbose
+  -h, --help            show help
+`,
+		remoteCommand: `Usage: tool remote <command> [flags]
+
+Manage remotes.
+Remotes are tracked repositories.
+
+Commands:
+  add  Add a remote.
+
+Flags:
+  -c, --config <value>  config file
+  -h, --help            show help
+`,
+		addCommand: `Usage: tool remote add [flags] <name> [<urls>...]
+
+Add a remote.
+
+Arguments:
+  name     name of the remote (required)
+  urls...
+
+Flags:
+  -b, --branch <name>   branch to track (default: master)
+  -c, --config <value>  config file
+      --no-fetch
+  -h, --host <value>
+      --help            show help
+`,
+		nil: `Usage: tool <command>
+
+Commands:
+  remote  Manage remotes.
+
+Flags:
+  -h, --help  show help
+`,
+	}
+
+	for command, expected := range usages {
+		if usage := registry.Help("tool", command); usage != expected {
+			t.Errorf("unexpected usage text:\n%s\nexpected:\n%s", usage, expected)
+		}
+	}
+
+	// built-in help flag
+	if _, err := registry.Parse([]string{"remote", "--help"}); err != (ErrorHelpRequested{"remote", registry.Help(programName(), remoteCommand)}) {
+		t.Errorf("expected help requested error, got %#v", err)
+	}
+
+	if _, err := registry.Parse([]string{"-vh"}); err != (ErrorHelpRequested{"", registry.Help(programName(), rootCommand)}) {
+		t.Errorf("expected help requested error, got %#v", err)
+	}
+
+	// the usage text of a sub-command is the same as the text of its help flag
+	if _, err := registry.Parse([]string{"remote", "add", "--help"}); err != (ErrorHelpRequested{"remote add", registry.Help(programName(), addCommand)}) {
+		t.Errorf("expected help requested error, got %#v", err)
+	}
+
+	// overridden short help flag
+	if _, err := registry.Parse([]string{"remote", "add", "origin", "-h", "localhost"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+// MIT License
+
+// Copyright (c) 2020 Uday Hiwarale
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package clapper
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrorAmbiguous represents an error when a prefix of a command name or a long flag name in the command-line arguments
+// matches more than one registered command or flag (see the `AllowPrefixes` field of the `Registry`).
+// The `Candidates` field contains the matching command names or flags.
+type ErrorAmbiguous struct {
+	Name       string
+	Candidates []string
+}
+
+func (e ErrorAmbiguous) Error() string {
+	return fmt.Sprintf("ambiguous %s found in the arguments (matches %s)", e.Name, strings.Join(e.Candidates, ", "))
+}
+
+/*---------------------*/
+
+// check if the command is registered with the alias
+func (commandConfig *CommandConfig) hasAlias(alias string) bool {
+
+	for _, _alias := range commandConfig.Aliases {
+		if _alias == alias {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shadowingCommand returns the command among the `commands` (other than the command itself)
+// registered with the `name` as its name or an alias, or `nil` if there is no such command
+func (commandConfig *CommandConfig) shadowingCommand(commands map[string]*CommandConfig, name string) *CommandConfig {
+
+	for _, commandName := range sortedCommandNames(commands) {
+		if command := commands[commandName]; command != commandConfig && (commandName == name || command.hasAlias(name)) {
+			return command
+		}
+	}
+
+	return nil
+}
+
+// addAliases adds the aliases not registered yet to the command registered among the `commands`.
+// An alias used by another command among the `commands` (as its name or an alias) is a programming error, hence it panics.
+func (commandConfig *CommandConfig) addAliases(commands map[string]*CommandConfig, aliases []string) {
+
+	for _, alias := range aliases {
+		if alias = removeWhitespaces(alias); len(alias) == 0 || alias == commandConfig.Name || commandConfig.hasAlias(alias) {
+			continue
+		}
+
+		if command := commandConfig.shadowingCommand(commands, alias); command != nil {
+			panic(fmt.Sprintf("clapper: alias %s of command %s is already used by command %s", strconv.Quote(alias), strconv.Quote(commandConfig.Path), strconv.Quote(command.Path)))
+		}
+
+		commandConfig.Aliases = append(commandConfig.Aliases, alias)
+	}
+}
+
+// checkName panics if the name of a new command is used by another command among the `commands` as an alias
+func (commandConfig *CommandConfig) checkName(commands map[string]*CommandConfig) {
+
+	if command := commandConfig.shadowingCommand(commands, commandConfig.Name); command != nil && len(commandConfig.Name) != 0 {
+		panic(fmt.Sprintf("clapper: name of command %s is already used by command %s as an alias", strconv.Quote(commandConfig.Path), strconv.Quote(command.Path)))
+	}
+}
+
+// sorted names of the commands
+func sortedCommandNames(commands map[string]*CommandConfig) []string {
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// matches returns the names (or their prefixes if `AllowPrefixes` is set) matching the value
+func (registry *Registry) matches(value string, names []string) []string {
+
+	matches := make([]string, 0)
+	for _, name := range names {
+		if name == value || (registry.AllowPrefixes && strings.HasPrefix(name, value)) {
+			matches = append(matches, name)
+		}
+	}
+
+	sort.Strings(matches)
+
+	return matches
+}
+
+// lookupCommand returns the command among the `commands` matched by the name, an alias
+// or a unique prefix of the name or an alias of a command (if `AllowPrefixes` is set).
+// If no command is matched, it returns `ErrorUnknownCommand` error. If more than one command is matched by a prefix,
+// it returns `ErrorAmbiguous` error.
+func (registry *Registry) lookupCommand(commands map[string]*CommandConfig, name string) (*CommandConfig, error) {
+
+	// exact name
+	if commandConfig, ok := commands[name]; ok {
+		return commandConfig, nil
+	}
+
+	if len(name) == 0 {
+		return nil, ErrorUnknownCommand{name, nil}
+	}
+
+	// exact alias (unique among the commands, see `addAliases`)
+	for _, commandName := range sortedCommandNames(commands) {
+		if commandConfig := commands[commandName]; commandConfig.hasAlias(name) {
+			return commandConfig, nil
+		}
+	}
+
+	// prefix of a name or an alias
+	if registry.AllowPrefixes {
+		matched := make(map[string]*CommandConfig)
+
+		for commandName, commandConfig := range commands {
+			if len(commandName) != 0 && len(registry.matches(name, append([]string{commandName}, commandConfig.Aliases...))) > 0 {
+				matched[commandName] = commandConfig
+			}
+		}
+
+		if len(matched) == 1 {
+			for _, commandConfig := range matched {
+				return commandConfig, nil
+			}
+		}
+
+		if len(matched) > 1 {
+			candidates := make([]string, 0, len(matched))
+			for commandName := range matched {
+				candidates = append(candidates, commandName)
+			}
+
+			sort.Strings(candidates)
+
+			return nil, ErrorAmbiguous{name, candidates}
+		}
+	}
+
+	return nil, ErrorUnknownCommand{name, suggestCommands(name, commands)}
+}
+
+// isCommandName checks if the value matches a command among the `commands` (or matches more than one command)
+func (registry *Registry) isCommandName(commands map[string]*CommandConfig, value string) bool {
+
+	if len(value) == 0 {
+		return false
+	}
+
+	_, err := registry.lookupCommand(commands, value)
+	_, isAmbiguous := err.(ErrorAmbiguous)
+
+	return err == nil || isAmbiguous
+}
+
+// expandFlag returns the long flag (like `--verbose`) of the command matched by a unique prefix of a long flag
+// (like `--verb`) if `AllowPrefixes` is set, otherwise (or for a short flag) the value is returned as it is.
+// An inverted flag is matched by a prefix of the name with `no-` prefix and the built-in help flag by a prefix of `help`.
+// If more than one flag is matched, it returns `ErrorAmbiguous` error.
+func (registry *Registry) expandFlag(commandConfig *CommandConfig, value string) (string, error) {
+
+	if !registry.AllowPrefixes || isShortFlag(value) {
+		return value, nil
+	}
+
+	// long flag names (as they appear in the command-line arguments)
+	names := make([]string, 0, len(commandConfig.Flags)+1)
+	for _, flagName := range commandConfig.flagNames() {
+		if commandConfig.Flags[flagName].IsInverted {
+			names = append(names, "no-"+flagName)
+		} else {
+			names = append(names, flagName)
+		}
+	}
+
+	// built-in help flag
+	if _, ok := commandConfig.Flags["help"]; !ok {
+		names = append(names, "help")
+	}
+
+	name := strings.TrimPrefix(value, "--")
+
+	// an exact name takes precedence over the longer names
+	matches := registry.matches(name, names)
+	for _, match := range matches {
+		if match == name {
+			return value, nil
+		}
+	}
+
+	if len(matches) == 1 {
+		return "--" + matches[0], nil
+	}
+
+	if len(matches) > 1 {
+		candidates := make([]string, 0, len(matches))
+		for _, match := range matches {
+			candidates = append(candidates, "--"+match)
+		}
+
+		return "", ErrorAmbiguous{value, candidates}
+	}
+
+	return value, nil
+}
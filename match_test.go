@@ -0,0 +1,154 @@
+package clapper
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// test command aliases
+func TestAliases(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	rootCommand, _ := registry.Register("")
+	rootCommand.AddFlag("verbose", "v", true, "")
+	rootCommand.AddFlag("version", "V", false, "")
+
+	remoteCommand, _ := registry.Register("remote")
+	removeCommand, _ := remoteCommand.Register("remove", "rm", "delete")
+	removeCommand.AddArg("name", "")
+	removeCommand.AddFlag("no-prune", "", true, "")
+	removeCommand.AddFlag("force", "f", true, "")
+	remoteCommand.Register("rename", "mv")
+
+	registry.Register("status", "st")
+	registry.Register("stash")
+
+	// aliases are added to a registered command
+	if command, ok := remoteCommand.Register("remove", "del", "rm"); !ok || command != removeCommand || !reflect.DeepEqual(command.Aliases, []string{"rm", "delete", "del"}) {
+		t.Errorf("unexpected aliases: %#v", command.Aliases)
+	}
+
+	for _, alias := range []string{"remove", "rm", "delete", "del"} {
+		if command, err := registry.Parse([]string{"remote", alias, "origin"}); err != nil || command != removeCommand || command.Args["name"].Value != "origin" {
+			t.Errorf("expected `remote remove` command for %s, got %#v (%v)", alias, command, err)
+		}
+	}
+
+	if command, err := registry.Parse([]string{"-v", "st"}); err != nil || command.Name != "status" {
+		t.Errorf("expected `status` command, got %#v (%v)", command, err)
+	}
+
+	// aliases shadowing other commands at the same level are rejected
+	conflicts := map[string]func(){
+		`alias "rm" of command "remote rmdir" is already used by command "remote remove"`: func() { remoteCommand.Register("rmdir", "rm") },
+		`alias "status" of command "list" is already used by command "status"`:            func() { registry.Register("list", "status") },
+		`alias "st" of command "stash" is already used by command "status"`:               func() { registry.Register("stash", "st") },
+		`name of command "st" is already used by command "status" as an alias`:            func() { registry.Register("st") },
+	}
+
+	for expected, register := range conflicts {
+		func() {
+			defer func() {
+				if message := recover(); message != "clapper: "+expected {
+					t.Errorf("expected panic %#v, got %#v", expected, message)
+				}
+			}()
+
+			register()
+		}()
+	}
+
+	if _, ok := remoteCommand.SubCommands["rmdir"]; ok {
+		t.Errorf("unexpected `remote rmdir` command")
+	}
+
+	// prefixes are not accepted by default
+	if _, err := registry.Parse([]string{"remote", "rem"}); !reflect.DeepEqual(err, ErrorUnknownCommand{"rem", []string{"remove"}}) {
+		t.Errorf("expected unknown command error, got %#v", err)
+	}
+
+	if _, err := registry.Parse([]string{"--verb"}); !reflect.DeepEqual(err, ErrorUnknownFlag{"--verb", []string{"--verbose"}}) {
+		t.Errorf("expected unknown flag error, got %#v", err)
+	}
+
+	// aliases are listed in the usage text
+	if usage := registry.Help("tool", registry.Commands["remote"]); !strings.Contains(usage, "  remove, rm, delete, del\n") || !strings.Contains(usage, "  rename, mv\n") {
+		t.Errorf("expected aliases in the usage text, got %s", usage)
+	}
+}
+
+// test unique prefixes of commands and flags
+func TestPrefixes(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+	registry.AllowPrefixes = true
+
+	rootCommand, _ := registry.Register("")
+	rootCommand.AddFlag("verbose", "v", true, "")
+	rootCommand.AddFlag("version", "V", false, "")
+
+	remoteCommand, _ := registry.Register("remote")
+	removeCommand, _ := remoteCommand.Register("remove", "rm", "delete")
+	removeCommand.AddArg("name", "")
+	removeCommand.AddFlag("no-prune", "", true, "")
+	removeCommand.AddFlag("force", "f", true, "")
+	remoteCommand.Register("rename", "mv")
+
+	registry.Register("status", "st")
+	registry.Register("stash")
+
+	result, err := registry.ParseArgs([]string{"--verb", "remote", "remo", "origin", "--no-p", "--fo"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Command.Path != "remote remove" || result.RootFlags["verbose"].Value != "true" {
+		t.Errorf("expected `remote remove` command, got %#v", result.Command)
+	}
+
+	if result.Flags["prune"].Value != "false" || result.Flags["force"].Value != "true" {
+		t.Errorf("unexpected flag values: %#v", result.Flags)
+	}
+
+	// a prefix of an alias
+	if result, err := registry.ParseArgs([]string{"remote", "del", "origin"}); err != nil || result.Command.Path != "remote remove" {
+		t.Errorf("expected `remote remove` command, got %#v (%v)", result, err)
+	}
+
+	// an exact name takes precedence over the longer names
+	if result, err := registry.ParseArgs([]string{"st"}); err != nil || result.Command.Name != "status" {
+		t.Errorf("expected `status` command, got %#v (%v)", result, err)
+	}
+
+	// built-in help flag
+	if _, err := registry.ParseArgs([]string{"remote", "--he"}); err == nil {
+		t.Errorf("expected help error")
+	} else if _, ok := err.(ErrorHelpRequested); !ok {
+		t.Errorf("expected help error, got %#v", err)
+	}
+
+	errs := []struct {
+		values   []string
+		expected error
+	}{
+		{[]string{"sta"}, ErrorAmbiguous{"sta", []string{"stash", "status"}}},
+		{[]string{"remote", "re"}, ErrorAmbiguous{"re", []string{"remove", "rename"}}},
+		{[]string{"--ver"}, ErrorAmbiguous{"--ver", []string{"--verbose", "--version"}}},
+		{[]string{"--ver", "status"}, ErrorAmbiguous{"--ver", []string{"--verbose", "--version"}}},
+		{[]string{"remote", "remove", "--pr"}, ErrorUnknownFlag{"--pr", []string{"--no-prune"}}},
+	}
+
+	for _, e := range errs {
+		if _, err := registry.ParseArgs(e.values); !reflect.DeepEqual(err, e.expected) {
+			t.Errorf("expected %#v, got %#v", e.expected, err)
+		}
+	}
+
+	if message := (ErrorAmbiguous{"sta", []string{"stash", "status"}}).Error(); message != "ambiguous sta found in the arguments (matches stash, status)" {
+		t.Errorf("unexpected error message: %s", message)
+	}
+}
@@ -0,0 +1,166 @@
+// MIT License
+
+// Copyright (c) 2020 Uday Hiwarale
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package clapper
+
+import (
+	"sort"
+	"strings"
+)
+
+// maximum edit distance of a suggestion
+const maxSuggestionDistance = 2
+
+// edit (Levenshtein) distance between two strings
+func editDistance(a, b string) int {
+
+	previous := make([]int, len(b)+1)
+	for index := range previous {
+		previous[index] = index
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			// deletion, insertion or substitution
+			current[j] = previous[j] + 1
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+
+			if previous[j-1]+cost < current[j] {
+				current[j] = previous[j-1] + cost
+			}
+		}
+
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+// suggestions for a mistyped `name` among the `candidates` (keyed by the name to compare, valued by the suggestion)
+// a candidate is suggested if it is within the maximum edit distance or one of the names is a prefix of the other
+func suggest(name string, candidates map[string]string) []string {
+
+	if len(name) == 0 {
+		return nil
+	}
+
+	// closest distance of each suggestion
+	distances := make(map[string]int)
+
+	for candidate, suggestion := range candidates {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+
+		if distance > maxSuggestionDistance && !strings.HasPrefix(candidate, name) && !strings.HasPrefix(name, candidate) {
+			continue
+		}
+
+		if d, ok := distances[suggestion]; !ok || distance < d {
+			distances[suggestion] = distance
+		}
+	}
+
+	if len(distances) == 0 {
+		return nil
+	}
+
+	suggestions := make([]string, 0, len(distances))
+	for suggestion := range distances {
+		suggestions = append(suggestions, suggestion)
+	}
+
+	// closest suggestions first
+	sort.Slice(suggestions, func(i, j int) bool {
+		if distances[suggestions[i]] != distances[suggestions[j]] {
+			return distances[suggestions[i]] < distances[suggestions[j]]
+		}
+
+		return suggestions[i] < suggestions[j]
+	})
+
+	return suggestions
+}
+
+// suggestions for an unknown command name among the `commands`
+// a command is suggested by its name if the name or one of its aliases is close to the unknown name
+func suggestCommands(name string, commands map[string]*CommandConfig) []string {
+
+	candidates := make(map[string]string)
+	for commandName, command := range commands {
+		if len(commandName) != 0 {
+			candidates[commandName] = commandName
+
+			for _, alias := range command.Aliases {
+				candidates[alias] = commandName
+			}
+		}
+	}
+
+	return suggest(name, candidates)
+}
+
+// suggestions for an unknown flag (like `--verbos` or `-V`) among the flags of the command
+func (commandConfig *CommandConfig) suggestFlags(value string) []string {
+
+	candidates := make(map[string]string)
+
+	// a short flag is suggested for a short flag with different case
+	if isShortFlag(value) {
+		for shortName := range commandConfig.flagsShort {
+			if shortName != value[1:] && strings.EqualFold(shortName, value[1:]) {
+				candidates[shortName] = "-" + shortName
+			}
+		}
+
+		return suggest(value[1:], candidates)
+	}
+
+	for flagName, flag := range commandConfig.Flags {
+		if flag.IsInverted {
+			candidates[flagName] = "--no-" + flagName
+			candidates["no-"+flagName] = "--no-" + flagName
+		} else {
+			candidates[flagName] = "--" + flagName
+		}
+	}
+
+	// built-in help flag
+	if _, ok := commandConfig.Flags["help"]; !ok {
+		candidates["help"] = "--help"
+	}
+
+	return suggest(strings.TrimLeft(value, "-"), candidates)
+}
+
+// unknown flag error of the command with suggestions
+func (commandConfig *CommandConfig) unknownFlag(value string) ErrorUnknownFlag {
+	return ErrorUnknownFlag{value, commandConfig.suggestFlags(value)}
+}
@@ -0,0 +1,82 @@
+package clapper
+
+import (
+	"reflect"
+	"testing"
+)
+
+// test edit distance
+func TestEditDistance(t *testing.T) {
+
+	distances := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"info", "info", 0},
+		{"infp", "info", 1},
+		{"inf", "info", 1},
+		{"verbos", "verbose", 1},
+		{"remove", "rmeove", 2},
+		{"kitten", "sitting", 3},
+		{"", "add", 3},
+	}
+
+	for _, d := range distances {
+		if distance := editDistance(d.a, d.b); distance != d.expected {
+			t.Errorf("expected distance %d between %q and %q, got %d", d.expected, d.a, d.b, distance)
+		}
+	}
+}
+
+// test suggestions of unknown commands and flags
+func TestSuggestions(t *testing.T) {
+
+	// create a new registry
+	registry := NewRegistry()
+
+	rootCommand, _ := registry.Register("")
+	rootCommand.AddFlag("verbose", "v", true, "")
+	rootCommand.AddFlag("version", "V", false, "")
+
+	infoCommand, _ := registry.Register("info")
+	infoCommand.AddArg("category", "")
+	infoCommand.AddFlag("output", "o", false, "")
+	infoCommand.AddFlag("no-clean", "", true, "")
+	registry.Register("init")
+
+	remoteCommand, _ := registry.Register("remote")
+	remoteCommand.Register("remove", "delete")
+
+	errs := []struct {
+		values   []string
+		expected error
+	}{
+		{[]string{"infp"}, ErrorUnknownCommand{"infp", []string{"info", "init"}}},
+		{[]string{"information"}, ErrorUnknownCommand{"information", []string{"info"}}},
+		{[]string{"remote", "remvoe"}, ErrorUnknownCommand{"remvoe", []string{"remove"}}},
+		{[]string{"remote", "delte"}, ErrorUnknownCommand{"delte", []string{"remove"}}},
+		{[]string{"--verbos"}, ErrorUnknownFlag{"--verbos", []string{"--verbose"}}},
+		{[]string{"--hlep"}, ErrorUnknownFlag{"--hlep", []string{"--help"}}},
+		{[]string{"-O"}, ErrorUnknownFlag{"-O", nil}},
+		{[]string{"info", "student", "-O"}, ErrorUnknownFlag{"-O", []string{"-o"}}},
+		{[]string{"info", "student", "--clean"}, ErrorUnknownFlag{"--clean", []string{"--no-clean"}}},
+		{[]string{"info", "student", "--xyz"}, ErrorUnknownFlag{"--xyz", nil}},
+	}
+
+	for _, e := range errs {
+		if _, err := registry.Parse(e.values); !reflect.DeepEqual(err, e.expected) {
+			t.Errorf("expected %#v, got %#v", e.expected, err)
+		}
+	}
+
+	// suggestions are included in the error message
+	err := ErrorUnknownCommand{"infp", []string{"info", "init"}}
+	if message := err.Error(); message != "unknown command infp found in the arguments (did you mean info, init?)" {
+		t.Errorf("unexpected error message: %s", message)
+	}
+
+	if message := (ErrorUnknownFlag{"--xyz", nil}).Error(); message != "unknown flag --xyz found in the arguments" {
+		t.Errorf("unexpected error message: %s", message)
+	}
+}
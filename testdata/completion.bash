@@ -0,0 +1,60 @@
+# bash completion for tool
+
+_tool_completion() {
+    local cur prev word path i
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+    path=""
+
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        word="${COMP_WORDS[i]}"
+        case "${path}:${word}" in
+            ":push"|":remote"|":status"|"remote:add"|"remote:remove")
+                path="${path:+${path} }${word}"
+                ;;
+            ":st")
+                path="status"
+                ;;
+            "remote:rm")
+                path="remote remove"
+                ;;
+        esac
+    done
+
+    case "${path}:${prev}" in
+        "push:--set-upstream"|"push:-u")
+            COMPREPLY=($(compgen -W "$(tool __complete "${COMP_WORDS[@]:1:COMP_CWORD}")" -- "${cur}"))
+            return
+            ;;
+        ":--config"|":-c"|"push:--config"|"push:-c"|"remote:--config"|"remote:-c"|"remote add:--branch"|"remote add:-b"|"remote add:--config"|"remote add:-c"|"remote remove:--config"|"remote remove:-c"|"status:--config"|"status:-c")
+            COMPREPLY=()
+            return
+            ;;
+    esac
+
+    local words=""
+    case "${path}" in
+        "")
+            words="push remote status --config -c --verbose -v --help -h"
+            ;;
+        "push")
+            words="$(tool __complete "${COMP_WORDS[@]:1:COMP_CWORD}")"
+            ;;
+        "remote")
+            words="add remove --config -c --help -h"
+            ;;
+        "remote add")
+            words="--branch -b --config -c --no-fetch --help -h"
+            ;;
+        "remote remove")
+            words="$(tool __complete "${COMP_WORDS[@]:1:COMP_CWORD}")"
+            ;;
+        "status")
+            words="--config -c --help -h"
+            ;;
+    esac
+
+    COMPREPLY=($(compgen -W "${words}" -- "${cur}"))
+}
+
+complete -o default -F _tool_completion tool
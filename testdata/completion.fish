@@ -0,0 +1,60 @@
+# fish completion for tool
+
+function __tool_path
+    set -l path ""
+    for word in (commandline -opc)[2..-1]
+        switch "$path:$word"
+            case ":push" ":remote" ":status" "remote:add" "remote:remove"
+                set path (string trim -- "$path $word")
+            case ":st"
+                set path "status"
+            case "remote:rm"
+                set path "remote remove"
+        end
+    end
+    echo $path
+end
+
+function __tool_using_path
+    set -l path (__tool_path)
+    test "$argv[1]" = "$path"
+end
+
+# tool
+complete -c tool -n '__tool_using_path ""' -f
+complete -c tool -n '__tool_using_path ""' -f -a 'push'
+complete -c tool -n '__tool_using_path ""' -f -a 'remote' -d 'Manage remotes'
+complete -c tool -n '__tool_using_path ""' -f -a 'status'
+complete -c tool -n '__tool_using_path ""' -l config -s c -r
+complete -c tool -n '__tool_using_path ""' -l verbose -s v
+complete -c tool -n '__tool_using_path ""' -l help -s h -d 'show help'
+
+# tool push
+complete -c tool -n '__tool_using_path "push"' -f -a '(tool __complete (commandline -opc)[2..-1] (commandline -ct))'
+complete -c tool -n '__tool_using_path "push"' -l config -s c -r
+complete -c tool -n '__tool_using_path "push"' -l force -s f
+complete -c tool -n '__tool_using_path "push"' -l set-upstream -s u -r -f -a '(tool __complete (commandline -opc)[2..-1] (commandline -ct))'
+complete -c tool -n '__tool_using_path "push"' -l help -s h -d 'show help'
+
+# tool remote
+complete -c tool -n '__tool_using_path "remote"' -f
+complete -c tool -n '__tool_using_path "remote"' -f -a 'add' -d 'Add a remote'
+complete -c tool -n '__tool_using_path "remote"' -f -a 'remove'
+complete -c tool -n '__tool_using_path "remote"' -l config -s c -r
+complete -c tool -n '__tool_using_path "remote"' -l help -s h -d 'show help'
+
+# tool remote add
+complete -c tool -n '__tool_using_path "remote add"' -l branch -s b -r -d 'Branch to track'
+complete -c tool -n '__tool_using_path "remote add"' -l config -s c -r
+complete -c tool -n '__tool_using_path "remote add"' -l no-fetch -d 'Don'\''t fetch the remote'
+complete -c tool -n '__tool_using_path "remote add"' -l help -s h -d 'show help'
+
+# tool remote remove
+complete -c tool -n '__tool_using_path "remote remove"' -f -a '(tool __complete (commandline -opc)[2..-1] (commandline -ct))'
+complete -c tool -n '__tool_using_path "remote remove"' -l config -s c -r
+complete -c tool -n '__tool_using_path "remote remove"' -l help -s h -d 'show help'
+
+# tool status
+complete -c tool -n '__tool_using_path "status"' -f
+complete -c tool -n '__tool_using_path "status"' -l config -s c -r
+complete -c tool -n '__tool_using_path "status"' -l help -s h -d 'show help'
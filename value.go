@@ -0,0 +1,395 @@
+// MIT License
+
+// Copyright (c) 2020 Uday Hiwarale
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package clapper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValueType represents the type of the value of a flag or an argument.
+type ValueType int
+
+// types of the value of a flag or an argument
+const (
+	TypeString      ValueType = iota // string value (default)
+	TypeInt                          // `int` value
+	TypeInt64                        // `int64` value
+	TypeUint                         // `uint` value
+	TypeUint64                       // `uint64` value
+	TypeFloat64                      // `float64` value
+	TypeBool                         // `bool` value
+	TypeDuration                     // `time.Duration` value, such as "1h30m"
+	TypeStringSlice                  // comma-separated list of strings, such as "a,b,c"
+)
+
+// names of the value types
+var valueTypeNames = map[ValueType]string{
+	TypeString:      "string",
+	TypeInt:         "int",
+	TypeInt64:       "int64",
+	TypeUint:        "uint",
+	TypeUint64:      "uint64",
+	TypeFloat64:     "float64",
+	TypeBool:        "bool",
+	TypeDuration:    "duration",
+	TypeStringSlice: "[]string",
+}
+
+// String returns the name of the value type.
+func (valueType ValueType) String() string {
+	if name, ok := valueTypeNames[valueType]; ok {
+		return name
+	}
+
+	return "unknown"
+}
+
+// ValueSource represents the source of the effective value of a flag or an argument.
+type ValueSource int
+
+// sources of the effective value of a flag or an argument (in the order of precedence)
+const (
+	SourceDefault ValueSource = iota // default value (the value is not set)
+	SourceConfig                     // config file
+	SourceEnv                        // environment variable
+	SourceArgv                       // command-line arguments
+)
+
+// names of the value sources
+var valueSourceNames = map[ValueSource]string{
+	SourceDefault: "default",
+	SourceConfig:  "config",
+	SourceEnv:     "env",
+	SourceArgv:    "argv",
+}
+
+// String returns the name of the value source.
+func (valueSource ValueSource) String() string {
+	if name, ok := valueSourceNames[valueSource]; ok {
+		return name
+	}
+
+	return "unknown"
+}
+
+// check if the `valueType` type is a numeric type
+func isNumericType(valueType ValueType) bool {
+	switch valueType {
+	case TypeInt, TypeInt64, TypeUint, TypeUint64, TypeFloat64:
+		return true
+	}
+
+	return false
+}
+
+// check if value can be converted to the `valueType` type
+func isValidValue(value string, valueType ValueType) bool {
+
+	var err error
+
+	switch valueType {
+	case TypeInt:
+		_, err = strconv.ParseInt(value, 0, strconv.IntSize)
+	case TypeInt64:
+		_, err = strconv.ParseInt(value, 0, 64)
+	case TypeUint:
+		_, err = strconv.ParseUint(value, 0, strconv.IntSize)
+	case TypeUint64:
+		_, err = strconv.ParseUint(value, 0, 64)
+	case TypeFloat64:
+		_, err = strconv.ParseFloat(value, 64)
+	case TypeBool:
+		if _, ok := parseBoolLiteral(value); !ok {
+			return false
+		}
+	case TypeDuration:
+		_, err = time.ParseDuration(value)
+	}
+
+	return err == nil
+}
+
+// boolean literals accepted as the value of a boolean flag (like `--force=false`) or a `TypeBool` value
+var boolLiterals = map[string]bool{
+	"true":  true,
+	"false": false,
+	"1":     true,
+	"0":     false,
+	"yes":   true,
+	"no":    false,
+}
+
+// parse a boolean literal (case-insensitive)
+func parseBoolLiteral(value string) (v bool, ok bool) {
+	v, ok = boolLiterals[strings.ToLower(value)]
+	return
+}
+
+// split a comma-separated list of strings
+func splitStringSlice(value string) []string {
+	if len(value) == 0 {
+		return make([]string, 0)
+	}
+
+	return strings.Split(value, ",")
+}
+
+/*---------------------*/
+
+// ErrorInvalidValue represents an error when the value of a flag or an argument can not be converted to its registered type.
+// The `Name` field contains the long name of the flag (with `--` prefix) or the name of the argument,
+// and the `Source` field tells where the value came from.
+type ErrorInvalidValue struct {
+	Name   string
+	Value  string
+	Type   ValueType
+	Source ValueSource
+}
+
+// origins of the values in the error messages
+var valueSourceOrigins = map[ValueSource]string{
+	SourceDefault: "default value",
+	SourceConfig:  "config files",
+	SourceEnv:     "environment variables",
+	SourceArgv:    "arguments",
+}
+
+func (e ErrorInvalidValue) Error() string {
+	return fmt.Sprintf("invalid value %s of %s found in the %s (expected %s)", strconv.Quote(e.Value), e.Name, valueSourceOrigins[e.Source], e.Type)
+}
+
+/*---------------------*/
+
+// Effective returns the value of the flag if it is set (or assigned directly), otherwise the default value.
+// The `Source` field of the flag tells where the effective value came from.
+func (flag *Flag) Effective() string {
+	if flag.IsSet || len(flag.Value) != 0 {
+		return flag.Value
+	}
+
+	return flag.DefaultValue
+}
+
+// value of the flag (or the default value if the value is not set)
+func (flag *Flag) value() string {
+	return flag.Effective()
+}
+
+// invalid value error of the flag
+func (flag *Flag) invalidValue(valueType ValueType) error {
+	return ErrorInvalidValue{"--" + flag.Name, flag.value(), valueType, flag.Source}
+}
+
+// Int returns the value of the flag (or the default value) as an `int` value.
+// If the value is not a valid `int` value, it returns `ErrorInvalidValue` error.
+func (flag *Flag) Int() (int, error) {
+	v, err := strconv.ParseInt(flag.value(), 0, strconv.IntSize)
+	if err != nil {
+		return 0, flag.invalidValue(TypeInt)
+	}
+
+	return int(v), nil
+}
+
+// Int64 returns the value of the flag (or the default value) as an `int64` value.
+// If the value is not a valid `int64` value, it returns `ErrorInvalidValue` error.
+func (flag *Flag) Int64() (int64, error) {
+	v, err := strconv.ParseInt(flag.value(), 0, 64)
+	if err != nil {
+		return 0, flag.invalidValue(TypeInt64)
+	}
+
+	return v, nil
+}
+
+// Uint returns the value of the flag (or the default value) as an `uint` value.
+// If the value is not a valid `uint` value, it returns `ErrorInvalidValue` error.
+func (flag *Flag) Uint() (uint, error) {
+	v, err := strconv.ParseUint(flag.value(), 0, strconv.IntSize)
+	if err != nil {
+		return 0, flag.invalidValue(TypeUint)
+	}
+
+	return uint(v), nil
+}
+
+// Uint64 returns the value of the flag (or the default value) as an `uint64` value.
+// If the value is not a valid `uint64` value, it returns `ErrorInvalidValue` error.
+func (flag *Flag) Uint64() (uint64, error) {
+	v, err := strconv.ParseUint(flag.value(), 0, 64)
+	if err != nil {
+		return 0, flag.invalidValue(TypeUint64)
+	}
+
+	return v, nil
+}
+
+// Float64 returns the value of the flag (or the default value) as a `float64` value.
+// If the value is not a valid `float64` value, it returns `ErrorInvalidValue` error.
+func (flag *Flag) Float64() (float64, error) {
+	v, err := strconv.ParseFloat(flag.value(), 64)
+	if err != nil {
+		return 0, flag.invalidValue(TypeFloat64)
+	}
+
+	return v, nil
+}
+
+// Bool returns the value of the flag (or the default value) as a `bool` value.
+// If the value is not a valid `bool` value, it returns `ErrorInvalidValue` error.
+func (flag *Flag) Bool() (bool, error) {
+	v, ok := parseBoolLiteral(flag.value())
+	if !ok {
+		return false, flag.invalidValue(TypeBool)
+	}
+
+	return v, nil
+}
+
+// Duration returns the value of the flag (or the default value) as a `time.Duration` value.
+// If the value is not a valid `time.Duration` value, it returns `ErrorInvalidValue` error.
+func (flag *Flag) Duration() (time.Duration, error) {
+	v, err := time.ParseDuration(flag.value())
+	if err != nil {
+		return 0, flag.invalidValue(TypeDuration)
+	}
+
+	return v, nil
+}
+
+// StringSlice returns the comma-separated value of the flag (or the default value) as a slice of strings.
+func (flag *Flag) StringSlice() []string {
+	return splitStringSlice(flag.value())
+}
+
+/*---------------------*/
+
+// Effective returns the value of the argument if it is set (or assigned directly), otherwise the default value.
+// The `Source` field of the argument tells where the effective value came from.
+func (arg *Arg) Effective() string {
+	if arg.IsSet || len(arg.Value) != 0 {
+		return arg.Value
+	}
+
+	return arg.DefaultValue
+}
+
+// value of the argument (or the default value if the value is not set)
+func (arg *Arg) value() string {
+	return arg.Effective()
+}
+
+// invalid value error of the argument
+func (arg *Arg) invalidValue(valueType ValueType) error {
+	return ErrorInvalidValue{arg.Name, arg.value(), valueType, arg.Source}
+}
+
+// Int returns the value of the argument (or the default value) as an `int` value.
+// If the value is not a valid `int` value, it returns `ErrorInvalidValue` error.
+func (arg *Arg) Int() (int, error) {
+	v, err := strconv.ParseInt(arg.value(), 0, strconv.IntSize)
+	if err != nil {
+		return 0, arg.invalidValue(TypeInt)
+	}
+
+	return int(v), nil
+}
+
+// Int64 returns the value of the argument (or the default value) as an `int64` value.
+// If the value is not a valid `int64` value, it returns `ErrorInvalidValue` error.
+func (arg *Arg) Int64() (int64, error) {
+	v, err := strconv.ParseInt(arg.value(), 0, 64)
+	if err != nil {
+		return 0, arg.invalidValue(TypeInt64)
+	}
+
+	return v, nil
+}
+
+// Uint returns the value of the argument (or the default value) as an `uint` value.
+// If the value is not a valid `uint` value, it returns `ErrorInvalidValue` error.
+func (arg *Arg) Uint() (uint, error) {
+	v, err := strconv.ParseUint(arg.value(), 0, strconv.IntSize)
+	if err != nil {
+		return 0, arg.invalidValue(TypeUint)
+	}
+
+	return uint(v), nil
+}
+
+// Uint64 returns the value of the argument (or the default value) as an `uint64` value.
+// If the value is not a valid `uint64` value, it returns `ErrorInvalidValue` error.
+func (arg *Arg) Uint64() (uint64, error) {
+	v, err := strconv.ParseUint(arg.value(), 0, 64)
+	if err != nil {
+		return 0, arg.invalidValue(TypeUint64)
+	}
+
+	return v, nil
+}
+
+// Float64 returns the value of the argument (or the default value) as a `float64` value.
+// If the value is not a valid `float64` value, it returns `ErrorInvalidValue` error.
+func (arg *Arg) Float64() (float64, error) {
+	v, err := strconv.ParseFloat(arg.value(), 64)
+	if err != nil {
+		return 0, arg.invalidValue(TypeFloat64)
+	}
+
+	return v, nil
+}
+
+// Bool returns the value of the argument (or the default value) as a `bool` value.
+// If the value is not a valid `bool` value, it returns `ErrorInvalidValue` error.
+func (arg *Arg) Bool() (bool, error) {
+	v, ok := parseBoolLiteral(arg.value())
+	if !ok {
+		return false, arg.invalidValue(TypeBool)
+	}
+
+	return v, nil
+}
+
+// Duration returns the value of the argument (or the default value) as a `time.Duration` value.
+// If the value is not a valid `time.Duration` value, it returns `ErrorInvalidValue` error.
+func (arg *Arg) Duration() (time.Duration, error) {
+	v, err := time.ParseDuration(arg.value())
+	if err != nil {
+		return 0, arg.invalidValue(TypeDuration)
+	}
+
+	return v, nil
+}
+
+// StringSlice returns the comma-separated value of the argument (or the default value) as a slice of strings.
+// The values of a variadic argument are returned as provided by the user, even if they contain comma (,).
+func (arg *Arg) StringSlice() []string {
+	if arg.IsVariadic && len(arg.Values) > 0 {
+		return append(make([]string, 0, len(arg.Values)), arg.Values...)
+	}
+
+	return splitStringSlice(arg.value())
+}